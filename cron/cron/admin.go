@@ -0,0 +1,237 @@
+package cron
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handler is an http.Handler to inspect and control a Cron at runtime. It is
+// safe to use while the Cron is running: it goes through the Cron's methods,
+// like any other caller.
+//
+// Entries can only be added for jobs registered with the Handler, under a
+// name. Mount it under a prefix with http.StripPrefix:
+//
+//	h := cron.NewHandler(c)
+//	h.RegisterFunc("backup", backup)
+//	http.Handle("/cron/", http.StripPrefix("/cron", h))
+type Handler struct {
+	cron *Cron
+
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+// NewHandler returns a Handler for the Cron.
+func NewHandler(c *Cron) *Handler {
+	return &Handler{cron: c, jobs: make(map[string]Job)}
+}
+
+// Register makes the job available to the entries added through the
+// Handler, under the name.
+func (h *Handler) Register(name string, job Job) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.jobs[name] = job
+}
+
+// RegisterFunc makes the func available to the entries added through the
+// Handler, under the name.
+func (h *Handler) RegisterFunc(name string, cmd func()) {
+	h.Register(name, FuncJob(cmd))
+}
+
+// entryJSON is the JSON representation of an entry. Zero times are left out.
+type entryJSON struct {
+	ID        EntryID    `json:"id"`
+	Name      string     `json:"name,omitempty"`
+	Spec      string     `json:"spec,omitempty"`
+	Schedule  string     `json:"schedule"`
+	Job       string     `json:"job"`
+	Next      *time.Time `json:"next,omitempty"`
+	Prev      *time.Time `json:"prev,omitempty"`
+	Paused    bool       `json:"paused,omitempty"`
+	Finished  *time.Time `json:"finished,omitempty"`
+	DependsOn []EntryID  `json:"dependsOn,omitempty"`
+}
+
+func newEntryJSON(e Entry) entryJSON {
+	optional := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+	return entryJSON{
+		ID:        e.ID,
+		Name:      e.Name,
+		Spec:      e.Spec,
+		Schedule:  Describe(e.Schedule),
+		Job:       fmt.Sprintf("%T", e.Job),
+		Next:      optional(e.Next),
+		Prev:      optional(e.Prev),
+		Paused:    e.Paused,
+		Finished:  optional(e.Finished),
+		DependsOn: e.DependsOn(),
+	}
+}
+
+// addRequest is the body of a request adding an entry.
+type addRequest struct {
+	Spec string `json:"spec"`
+	Job  string `json:"job"`
+	Name string `json:"name"` // optional, the entry is identified by its ID otherwise
+}
+
+// ServeHTTP serves the following JSON endpoints, relative to where the
+// Handler is mounted.
+//
+// GET /
+//
+// Lists the entries:
+//
+//	[{"id":1,"name":"backup","spec":"0 3 * * *","schedule":"At 03:00",
+//	  "job":"cron.FuncJob","next":"2022-12-10T03:00:00Z"}]
+//
+// POST /
+//
+// Adds an entry running a registered job on the spec, which is parsed with the
+// Cron's parser. The entry's name is optional: without one, the entry is
+// identified by its ID, so that a job may be added several times. A name
+// already in use gets a 409 Conflict. The request is either form encoded or
+// JSON:
+//
+//	curl -X POST localhost:8080/cron/ -d spec='0 3 * * *' -d job=backup
+//	curl -X POST localhost:8080/cron/ -H "Content-Type: application/json" -d '{"spec":"0 3 * * *","job":"backup"}'
+//
+// It responds with the new entry.
+//
+// GET /{id}
+//
+// Returns the entry.
+//
+// DELETE /{id}
+//
+// Removes the entry.
+//
+// POST /{id}/run
+//
+// Runs the entry's job immediately, see Cron.RunNow.
+//
+// Errors are reported as {"error":"..."}.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	fail := func(status int, err error) {
+		w.WriteHeader(status)
+		enc.Encode(struct {
+			Error string `json:"error"`
+		}{err.Error()})
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet:
+			entries := []entryJSON{}
+			for _, e := range h.cron.Entries() {
+				entries = append(entries, newEntryJSON(e))
+			}
+			enc.Encode(entries)
+		case http.MethodPost:
+			id, err := h.add(r)
+			if errors.Is(err, ErrDuplicateName) {
+				fail(http.StatusConflict, err)
+				return
+			}
+			if err != nil {
+				fail(http.StatusBadRequest, err)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			enc.Encode(newEntryJSON(h.cron.Entry(id)))
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			fail(http.StatusMethodNotAllowed, errors.New("only GET and POST are supported"))
+		}
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts) > 2 || len(parts) == 2 && parts[1] != "run" {
+		fail(http.StatusNotFound, fmt.Errorf("not found: %s", r.URL.Path))
+		return
+	}
+	entry := h.cron.Entry(EntryID(id))
+	if !entry.Valid() {
+		fail(http.StatusNotFound, fmt.Errorf("unknown entry %d", id))
+		return
+	}
+
+	if len(parts) == 2 {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			fail(http.StatusMethodNotAllowed, errors.New("only POST is supported"))
+			return
+		}
+		h.cron.RunNow(entry.ID)
+		w.WriteHeader(http.StatusAccepted)
+		enc.Encode(newEntryJSON(entry))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		enc.Encode(newEntryJSON(entry))
+	case http.MethodDelete:
+		h.cron.Remove(entry.ID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		fail(http.StatusMethodNotAllowed, errors.New("only GET and DELETE are supported"))
+	}
+}
+
+// add adds the entry described by the request.
+func (h *Handler) add(r *http.Request) (EntryID, error) {
+	req, err := decodeAddRequest(r)
+	if err != nil {
+		return 0, err
+	}
+	if req.Spec == "" || req.Job == "" {
+		return 0, errors.New("must specify spec and job")
+	}
+	h.mu.Lock()
+	job, ok := h.jobs[req.Job]
+	h.mu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("unknown job %q", req.Job)
+	}
+	return h.cron.AddJob(req.Spec, job, WithName(req.Name))
+}
+
+// decodeAddRequest decodes a form encoded or JSON request adding an entry.
+func decodeAddRequest(r *http.Request) (addRequest, error) {
+	// Content-Type 可能带着 charset 之类的参数
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		return addRequest{
+			Spec: r.FormValue("spec"),
+			Job:  r.FormValue("job"),
+			Name: r.FormValue("name"),
+		}, nil
+	}
+	var req addRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return req, fmt.Errorf("malformed request body: %v", err)
+	}
+	return req, nil
+}
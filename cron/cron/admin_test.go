@@ -0,0 +1,150 @@
+package cron
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serve(h http.Handler, method, path, contentType, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	cron := New(WithParser(secondParser), WithChain(), WithLogger(DiscardLogger))
+	ran := make(chan struct{}, 1)
+	h := NewHandler(cron)
+	h.RegisterFunc("backup", func() { ran <- struct{}{} })
+	cron.Start()
+	defer cron.Stop()
+
+	w := serve(h, http.MethodPost, "/", "application/json", `{"spec":"0 0 3 * * *","job":"backup"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body)
+	}
+	var added entryJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &added); err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != 1 || added.Name != "" || added.Spec != "0 0 3 * * *" || added.Job != "cron.FuncJob" {
+		t.Errorf("unexpected entry %+v", added)
+	}
+	if added.Next == nil || added.Prev != nil {
+		t.Errorf("expected only a next activation, got %v and %v", added.Next, added.Prev)
+	}
+
+	form := url.Values{"spec": {"@every 1h"}, "job": {"backup"}, "name": {"hourly"}}.Encode()
+	if w := serve(h, http.MethodPost, "/", "application/x-www-form-urlencoded; charset=UTF-8", form); w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body)
+	}
+
+	w = serve(h, http.MethodGet, "/", "", "")
+	var entries []entryJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Name == "hourly" && e.Schedule != "Every 1h" {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+
+	if w := serve(h, http.MethodPost, "/1/run", "", ""); w.Code != http.StatusAccepted {
+		t.Errorf("expected %d, got %d: %s", http.StatusAccepted, w.Code, w.Body)
+	}
+	select {
+	case <-time.After(OneSecond):
+		t.Error("expected the job to run")
+	case <-ran:
+	}
+
+	if w := serve(h, http.MethodDelete, "/1", "", ""); w.Code != http.StatusNoContent {
+		t.Errorf("expected %d, got %d: %s", http.StatusNoContent, w.Code, w.Body)
+	}
+	if entries := cron.Entries(); len(entries) != 1 || entries[0].Name != "hourly" {
+		t.Errorf("expected the entry to be removed, got %v", entries)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	cron := New()
+	cron.AddFunc("@hourly", func() {})
+	h := NewHandler(cron)
+	h.RegisterFunc("backup", func() {})
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		allow              string
+	}{
+		{http.MethodPost, "/", `{"spec":"bogus","job":"backup"}`, http.StatusBadRequest, ""},
+		{http.MethodPost, "/", `{"spec":"@hourly","job":"unknown"}`, http.StatusBadRequest, ""},
+		{http.MethodPost, "/", `{"job":"backup"}`, http.StatusBadRequest, ""},
+		{http.MethodPost, "/", `{`, http.StatusBadRequest, ""},
+		{http.MethodPut, "/", "", http.StatusMethodNotAllowed, "GET, POST"},
+		{http.MethodGet, "/2", "", http.StatusNotFound, ""},
+		{http.MethodGet, "/x", "", http.StatusNotFound, ""},
+		{http.MethodGet, "/1/pause", "", http.StatusNotFound, ""},
+		{http.MethodGet, "/1/run", "", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodPut, "/1", "", http.StatusMethodNotAllowed, "GET, DELETE"},
+	}
+	for _, test := range tests {
+		w := serve(h, test.method, test.path, "application/json", test.body)
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected %d, got %d", test.method, test.path, test.status, w.Code)
+		}
+		if allow := w.Header().Get("Allow"); allow != test.allow {
+			t.Errorf("%s %s: expected Allow %q, got %q", test.method, test.path, test.allow, allow)
+		}
+		var resp struct{ Error string }
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == "" {
+			t.Errorf("%s %s: expected an error, got %s", test.method, test.path, w.Body)
+		}
+	}
+	if n := len(cron.Entries()); n != 1 {
+		t.Errorf("expected no entry to be added, got %d entries", n)
+	}
+}
+
+// A job may be added several times, but a name only once.
+func TestHandlerDuplicateName(t *testing.T) {
+	cron := New()
+	h := NewHandler(cron)
+	h.RegisterFunc("backup", func() {})
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"spec":"* * * * *","job":"backup"}`, http.StatusCreated},
+		{`{"spec":"*/2 * * * *","job":"backup"}`, http.StatusCreated},
+		{`{"spec":"0 3 * * *","job":"backup","name":"nightly"}`, http.StatusCreated},
+		{`{"spec":"0 4 * * *","job":"backup","name":"nightly"}`, http.StatusConflict},
+	}
+	for _, test := range tests {
+		if w := serve(h, http.MethodPost, "/", "application/json", test.body); w.Code != test.status {
+			t.Errorf("%s: expected %d, got %d: %s", test.body, test.status, w.Code, w.Body)
+		}
+	}
+
+	keys := make(map[string]bool)
+	for _, e := range cron.Entries() {
+		keys[e.key()] = true
+	}
+	if len(keys) != 3 {
+		t.Errorf("expected 3 entries with distinct keys, got %v", keys)
+	}
+}
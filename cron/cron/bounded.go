@@ -0,0 +1,157 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxFinishedEntries is the number of finished entries a Cron remembers, see
+// Entry.Finished.
+const maxFinishedEntries = 100
+
+// atSchedule activates once, at a given time.
+type atSchedule struct {
+	t time.Time
+}
+
+// At returns a Schedule which activates once, at the given time. An entry
+// added with a time in the past never runs and finishes right away.
+func At(t time.Time) Schedule {
+	return atSchedule{t}
+}
+
+func (s atSchedule) Next(t time.Time) time.Time {
+	if s.t.After(t) {
+		return s.t
+	}
+	return time.Time{}
+}
+
+// timesSchedule is a Schedule whose entry runs at most n times.
+type timesSchedule struct {
+	Schedule
+	n int
+}
+
+// Times returns a Schedule activating like the given one, for at most n runs
+// of the entry it is added with. The Cron counts the runs: on its own the
+// returned Schedule has the activation times of the given one.
+func Times(schedule Schedule, n int) Schedule {
+	return timesSchedule{schedule, n}
+}
+
+// betweenSchedule restricts a Schedule to a window of time.
+type betweenSchedule struct {
+	Schedule
+	start, end time.Time
+}
+
+// Between returns a Schedule with the activations of the given one from start
+// until end, both included. A zero start or end leaves the window open on that
+// side.
+func Between(schedule Schedule, start, end time.Time) Schedule {
+	return betweenSchedule{schedule, start, end}
+}
+
+func (s betweenSchedule) Next(t time.Time) time.Time {
+	if !s.start.IsZero() && t.Before(s.start) {
+		t = s.start.Add(-time.Nanosecond)
+	}
+	next := s.Schedule.Next(t)
+	if next.IsZero() || !s.end.IsZero() && next.After(s.end) {
+		return time.Time{}
+	}
+	return next
+}
+
+// runLimit returns whether the schedule may run out of activations, and the
+// maximum number of runs it allows, or -1 if it has no such limit.
+func runLimit(schedule Schedule) (bounded bool, limit int) {
+	switch s := schedule.(type) {
+	case atSchedule:
+		return true, -1
+	case timesSchedule:
+		_, inner := runLimit(s.Schedule)
+		if inner >= 0 && inner < s.n {
+			return true, inner
+		}
+		return true, s.n
+	case betweenSchedule:
+		_, limit := runLimit(s.Schedule)
+		return true, limit
+	case jitterSchedule:
+		return runLimit(s.Schedule)
+	case excludingSchedule:
+		return runLimit(s.Schedule)
+	}
+	return false, -1
+}
+
+// exhausted reports whether the entry will never run again on its schedule.
+// Schedules which are not bounded, such as a spec which never matches, are
+// not considered exhausted.
+func (e *Entry) exhausted() bool {
+	if e.Paused {
+		return false
+	}
+	bounded, limit := runLimit(e.Schedule)
+	if limit >= 0 && e.runs >= limit {
+		return true
+	}
+	return bounded && e.Next.IsZero()
+}
+
+// finishExhausted moves the entries which are exhausted from the entries to
+// the finished ones. It is only called by the running scheduler.
+func (c *Cron) finishExhausted(now time.Time) {
+	var (
+		entries  []*Entry
+		finished []EntryID
+	)
+	for _, e := range c.entries {
+		if !e.exhausted() {
+			entries = append(entries, e)
+			continue
+		}
+		e.Next = time.Time{}
+		e.Finished = now
+		c.done = append(c.done, e)
+		finished = append(finished, e.ID)
+		c.logger.Info("finished", "entry", e.ID, "runs", e.runs)
+	}
+	if len(finished) == 0 {
+		return
+	}
+	c.entries = entries
+	if n := len(c.done) - maxFinishedEntries; n > 0 {
+		c.done = append([]*Entry(nil), c.done[n:]...)
+	}
+	for _, id := range finished {
+		c.dropDependency(id, now)
+	}
+	c.saveEntries()
+}
+
+// describeBounds describes the bounded schedules.
+func describeBounds(schedule Schedule) string {
+	const layout = "2006-01-02 15:04:05 MST"
+	switch s := schedule.(type) {
+	case atSchedule:
+		return "Once at " + s.t.Format(layout)
+	case timesSchedule:
+		if s.n == 1 {
+			return Describe(s.Schedule) + ", once"
+		}
+		return fmt.Sprintf("%s, %d times", Describe(s.Schedule), s.n)
+	case betweenSchedule:
+		desc := Describe(s.Schedule)
+		if !s.start.IsZero() {
+			desc += ", from " + s.start.Format(layout)
+		}
+		if !s.end.IsZero() {
+			desc += ", until " + s.end.Format(layout)
+		}
+		return desc
+	}
+	return ""
+}
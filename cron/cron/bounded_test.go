@@ -0,0 +1,110 @@
+package cron
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAt(t *testing.T) {
+	at := time.Date(2022, 12, 10, 3, 0, 0, 0, time.UTC)
+	s := At(at)
+	if next := s.Next(at.Add(-time.Second)); !next.Equal(at) {
+		t.Errorf("expected %v, got %v", at, next)
+	}
+	if next := s.Next(at); !next.IsZero() {
+		t.Errorf("expected no activation after %v, got %v", at, next)
+	}
+	if desc := Describe(s); desc != "Once at 2022-12-10 03:00:00 UTC" {
+		t.Errorf("unexpected description %q", desc)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	hourly, _ := ParseStandard("TZ=UTC @hourly")
+	start := time.Date(2022, 12, 10, 10, 0, 0, 0, time.UTC)
+	end := time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC)
+	s := Between(hourly, start, end)
+
+	tests := []struct {
+		from, expected time.Time
+	}{
+		{start.Add(-5 * time.Hour), start},
+		{start, start.Add(time.Hour)},
+		{start.Add(time.Hour), end},
+		{end, time.Time{}},
+	}
+	for _, test := range tests {
+		if next := s.Next(test.from); !next.Equal(test.expected) {
+			t.Errorf("from %v: expected %v, got %v", test.from, test.expected, next)
+		}
+	}
+
+	until := Between(hourly, time.Time{}, end)
+	if next := until.Next(start.Add(-5 * time.Hour)); !next.Equal(start.Add(-4 * time.Hour)) {
+		t.Errorf("expected the window to be open at the start, got %v", next)
+	}
+	if desc := Describe(until); desc != "At minute 0 past every hour (UTC), until 2022-12-10 12:00:00 UTC" {
+		t.Errorf("unexpected description %q", desc)
+	}
+}
+
+func TestExhausted(t *testing.T) {
+	now := time.Now()
+	every := Every(time.Minute)
+	tests := []struct {
+		name      string
+		entry     Entry
+		exhausted bool
+	}{
+		{"recurring", Entry{Schedule: every}, false},
+		{"unsatisfiable spec", Entry{Schedule: &SpecSchedule{}}, false},
+		{"at, pending", Entry{Schedule: At(now), Next: now}, false},
+		{"at, done", Entry{Schedule: At(now)}, true},
+		{"at, paused", Entry{Schedule: At(now), Paused: true}, false},
+		{"times, runs left", Entry{Schedule: Times(every, 2), Next: now, runs: 1}, false},
+		{"times, done", Entry{Schedule: Times(every, 2), Next: now, runs: 2}, true},
+		{"times, jittered", Entry{Schedule: Jitter(Times(every, 1), "key", time.Hour), Next: now, runs: 1}, true},
+		{"times of between", Entry{Schedule: Times(Between(every, now, now), 5), Next: now, runs: 1}, false},
+		{"between, done", Entry{Schedule: Between(every, time.Time{}, now)}, true},
+	}
+	for _, test := range tests {
+		if actual := test.entry.exhausted(); actual != test.exhausted {
+			t.Errorf("%s: expected exhausted %v, got %v", test.name, test.exhausted, actual)
+		}
+	}
+}
+
+func TestFinishedEntries(t *testing.T) {
+	cron := New(WithParser(secondParser), WithChain(), WithLogger(DiscardLogger))
+	var onceCalls, timesCalls int64
+	once := cron.Schedule(At(time.Now().Add(500*time.Millisecond)), FuncJob(func() { atomic.AddInt64(&onceCalls, 1) }))
+	spec, _ := secondParser.Parse("* * * * * ?")
+	times := cron.Schedule(Times(spec, 1), FuncJob(func() { atomic.AddInt64(&timesCalls, 1) }))
+	missed := cron.Schedule(At(time.Now().Add(-time.Hour)), FuncJob(func() {}))
+	recurring, _ := cron.AddFunc("@every 1h", func() {})
+	cron.Start()
+	defer cron.Stop()
+
+	<-time.After(OneSecond + 500*time.Millisecond)
+	if o, n := atomic.LoadInt64(&onceCalls), atomic.LoadInt64(&timesCalls); o != 1 || n != 1 {
+		t.Errorf("expected one run each, got %d and %d", o, n)
+	}
+	for _, id := range []EntryID{once, times, missed} {
+		entry := cron.Entry(id)
+		if entry.Finished.IsZero() || !entry.Next.IsZero() {
+			t.Errorf("expected entry %d to be finished, got %+v", id, entry)
+		}
+	}
+	if entry := cron.Entry(recurring); !entry.Finished.IsZero() {
+		t.Errorf("expected entry %d to go on, got %+v", recurring, entry)
+	}
+	if entries := cron.Entries(); len(entries) != 4 || entries[0].ID != recurring {
+		t.Errorf("expected the finished entries last, got %v", entries)
+	}
+
+	cron.Remove(once)
+	if entry := cron.Entry(once); entry.Valid() {
+		t.Errorf("expected the finished entry to be removed, got %+v", entry)
+	}
+}
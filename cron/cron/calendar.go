@@ -0,0 +1,369 @@
+package cron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxExclusionSkips bounds the number of excluded periods Next skips before it
+// gives up, e.g. for a yearly schedule whose only day is always excluded.
+const maxExclusionSkips = 1000
+
+// Calendar is a set of excluded days and periods, such as public holidays or a
+// change freeze. Days are calendar dates, which are excluded from midnight to
+// midnight in the time zone of the schedule being checked. Periods are ranges
+// of instants.
+//
+// A Calendar may be updated while the schedules using it are in use.
+type Calendar struct {
+	mu      sync.RWMutex
+	days    []dayRange
+	yearly  map[monthDay]bool
+	periods []period
+}
+
+// civilDate is a calendar date, without a time zone.
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) civilDate {
+	y, m, d := t.Date()
+	return civilDate{y, m, d}
+}
+
+func (d civilDate) before(o civilDate) bool {
+	if d.year != o.year {
+		return d.year < o.year
+	}
+	if d.month != o.month {
+		return d.month < o.month
+	}
+	return d.day < o.day
+}
+
+// dayRange is an inclusive range of dates.
+type dayRange struct {
+	from, to civilDate
+}
+
+type monthDay struct {
+	month time.Month
+	day   int
+}
+
+// period is the range of instants [start, end).
+type period struct {
+	start, end time.Time
+}
+
+// NewCalendar returns an empty Calendar.
+func NewCalendar() *Calendar {
+	return &Calendar{yearly: make(map[monthDay]bool)}
+}
+
+// AddDate excludes the date of t, e.g. a public holiday. Only the year, month
+// and day of t matter.
+func (c *Calendar) AddDate(t time.Time) {
+	c.AddDates(t, t)
+}
+
+// AddDates excludes the dates from the date of from to the date of to,
+// inclusive, e.g. a change freeze over the holidays.
+func (c *Calendar) AddDates(from, to time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.days = append(c.days, dayRange{dateOf(from), dateOf(to)})
+}
+
+// AddYearly excludes the date every year, e.g. December 25th.
+func (c *Calendar) AddYearly(month time.Month, day int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.yearly[monthDay{month, day}] = true
+}
+
+// AddPeriod excludes the instants from start, inclusive, to end, exclusive.
+func (c *Calendar) AddPeriod(start, end time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.periods = append(c.periods, period{start, end})
+}
+
+// Excludes returns true if t is excluded by the calendar. Dates are checked
+// in the time zone of t.
+func (c *Calendar) Excludes(t time.Time) bool {
+	_, excluded := c.excludedUntil(t)
+	return excluded
+}
+
+// excludedUntil returns the end of the exclusion containing t, if any. When
+// several overlap, it is the end of one of them, so the caller checks again.
+func (c *Calendar) excludedUntil(t time.Time) (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, p := range c.periods {
+		if !t.Before(p.start) && t.Before(p.end) {
+			return p.end, true
+		}
+	}
+	date := dateOf(t)
+	excluded := c.yearly[monthDay{date.month, date.day}]
+	for _, r := range c.days {
+		if !excluded && !date.before(r.from) && !r.to.before(date) {
+			excluded = true
+		}
+	}
+	if excluded {
+		return time.Date(date.year, date.month, date.day+1, 0, 0, 0, 0, t.Location()), true
+	}
+	return time.Time{}, false
+}
+
+// Excluding returns a Schedule which skips the activations of the schedule
+// excluded by the calendar, such as "every weekday at 09:00 except on public
+// holidays". The calendar's dates are checked in the time zone of the
+// schedule, e.g. the one given with CRON_TZ, or else of the activations.
+func Excluding(schedule Schedule, calendar *Calendar) Schedule {
+	return excludingSchedule{schedule, calendar}
+}
+
+type excludingSchedule struct {
+	Schedule
+	calendar *Calendar
+}
+
+// Next returns the first activation of the schedule after t which is not
+// excluded, skipping whole excluded days and periods at once.
+func (s excludingSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < maxExclusionSkips; i++ {
+		next := s.Schedule.Next(t)
+		if next.IsZero() {
+			return next
+		}
+		end, excluded := s.calendar.excludedUntil(next.In(scheduleLocation(s.Schedule, next)))
+		if !excluded {
+			return next
+		}
+		if _, ok := s.Schedule.(ConstantDelaySchedule); ok {
+			t = end // The delay counts from the end of the exclusion.
+			continue
+		}
+		// Resume just before the end, since Next is strictly after t.
+		t = end.Add(-time.Nanosecond)
+		if !t.After(next) {
+			t = next
+		}
+	}
+	return time.Time{}
+}
+
+// scheduleLocation returns the time zone the schedule works in.
+func scheduleLocation(schedule Schedule, t time.Time) *time.Location {
+	switch s := schedule.(type) {
+	case *SpecSchedule:
+		if s.Location != time.Local {
+			return s.Location
+		}
+	case dayRuleSchedule:
+		return scheduleLocation(s.SpecSchedule, t)
+	case jitterSchedule:
+		return scheduleLocation(s.Schedule, t)
+	case excludingSchedule:
+		return scheduleLocation(s.Schedule, t)
+	case timesSchedule:
+		return scheduleLocation(s.Schedule, t)
+	case betweenSchedule:
+		return scheduleLocation(s.Schedule, t)
+	}
+	return t.Location()
+}
+
+// AddICS excludes the events of the iCalendar (RFC 5545) data read from r,
+// such as a published calendar of public holidays. All day events exclude
+// their dates, other events the period between their start and end. Events
+// recurring every year on their own date (RRULE:FREQ=YEARLY, possibly with
+// INTERVAL=1 and the BYMONTH and BYMONTHDAY of their start) exclude their date
+// every year; other recurrences are not supported.
+//
+// The events are only added once all of them have been read: the calendar is
+// left unchanged if r holds an invalid one.
+func (c *Calendar) AddICS(r io.Reader) error {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return err
+	}
+	// 先全部解析到一个新的 Calendar 里面，出错的话 c 不会只加了一半
+	parsed := NewCalendar()
+	var event map[string]icsProperty
+	for n, line := range lines {
+		prop, err := parseICSProperty(line)
+		if err != nil {
+			return fmt.Errorf("ics line %d: %v", n+1, err)
+		}
+		switch {
+		case prop.name == "BEGIN" && prop.value == "VEVENT":
+			event = make(map[string]icsProperty)
+		case prop.name == "END" && prop.value == "VEVENT":
+			if event == nil {
+				return fmt.Errorf("ics line %d: END:VEVENT without BEGIN", n+1)
+			}
+			if err := parsed.addEvent(event); err != nil {
+				return fmt.Errorf("ics line %d: %v", n+1, err)
+			}
+			event = nil
+		case event != nil:
+			event[prop.name] = prop
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.days = append(c.days, parsed.days...)
+	for md := range parsed.yearly {
+		c.yearly[md] = true
+	}
+	c.periods = append(c.periods, parsed.periods...)
+	return nil
+}
+
+// addEvent excludes the dates or the period of the event.
+func (c *Calendar) addEvent(event map[string]icsProperty) error {
+	dtstart, ok := event["DTSTART"]
+	if !ok {
+		return fmt.Errorf("event without DTSTART")
+	}
+	start, allDay, err := dtstart.time()
+	if err != nil {
+		return err
+	}
+	yearly := false
+	if rrule, ok := event["RRULE"]; ok {
+		if !allDay || !isYearlyOn(rrule.value, start) {
+			return fmt.Errorf("unsupported recurrence: %s", rrule.value)
+		}
+		yearly = true
+	}
+
+	end := start
+	if dtend, ok := event["DTEND"]; ok {
+		if end, _, err = dtend.time(); err != nil {
+			return err
+		}
+	} else if allDay {
+		end = start.AddDate(0, 0, 1)
+	}
+
+	switch {
+	case yearly:
+		for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+			c.AddYearly(d.Month(), d.Day())
+		}
+	case allDay:
+		// DTEND is exclusive.
+		c.AddDates(start, end.AddDate(0, 0, -1))
+	case end.After(start):
+		c.AddPeriod(start, end)
+	default:
+		c.AddPeriod(start, start.Add(time.Second))
+	}
+	return nil
+}
+
+// isYearlyOn returns true if the RRULE value repeats every year on the month
+// and day of start, and nothing else.
+func isYearlyOn(rrule string, start time.Time) bool {
+	freq := false
+	for _, part := range strings.Split(strings.ToUpper(rrule), ";") {
+		eq := strings.Index(part, "=")
+		if eq < 0 {
+			return false
+		}
+		name, value := part[:eq], part[eq+1:]
+		switch {
+		case name == "FREQ" && value == "YEARLY":
+			freq = true
+		case name == "INTERVAL" && value == "1":
+		case name == "BYMONTH" && value == strconv.Itoa(int(start.Month())):
+		case name == "BYMONTHDAY" && value == strconv.Itoa(start.Day()):
+		default:
+			return false
+		}
+	}
+	return freq
+}
+
+// unfoldICS reads the content lines of iCalendar data, joining the lines
+// folded onto the following ones.
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// icsProperty is a content line: NAME;PARAM=VALUE:value
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseICSProperty(line string) (icsProperty, error) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return icsProperty{}, fmt.Errorf("invalid content line: %s", line)
+	}
+	parts := strings.Split(line[:colon], ";")
+	prop := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		if eq := strings.Index(param, "="); eq > 0 {
+			prop.params[strings.ToUpper(param[:eq])] = strings.Trim(param[eq+1:], `"`)
+		}
+	}
+	return prop, nil
+}
+
+// time parses a DATE or DATE-TIME value, and returns whether it was a DATE.
+// Dates are returned at midnight UTC, since only their year, month and day
+// matter. Date-times are UTC ("Z" suffix), in the TZID parameter's zone, or
+// else in the local time zone.
+func (p icsProperty) time() (time.Time, bool, error) {
+	if p.params["VALUE"] == "DATE" || len(p.value) == len("20060102") {
+		t, err := time.Parse("20060102", p.value)
+		return t, true, err
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse("20060102T150405Z", p.value)
+		return t, false, err
+	}
+	loc := time.Local
+	if tzid, ok := p.params["TZID"]; ok {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, false, fmt.Errorf("provided bad location %s: %v", tzid, err)
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", p.value, loc)
+	return t, false, err
+}
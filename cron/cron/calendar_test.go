@@ -0,0 +1,188 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarExcludes(t *testing.T) {
+	nyc, _ := time.LoadLocation("America/New_York")
+	cal := NewCalendar()
+	cal.AddDate(time.Date(2022, 12, 26, 0, 0, 0, 0, time.UTC))
+	cal.AddDates(time.Date(2022, 12, 30, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+	cal.AddYearly(time.July, 4)
+	cal.AddPeriod(time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC), time.Date(2022, 12, 10, 14, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		time     time.Time
+		expected bool
+	}{
+		{time.Date(2022, 12, 26, 0, 0, 0, 0, nyc), true},
+		{time.Date(2022, 12, 26, 23, 59, 59, 0, nyc), true},
+		{time.Date(2022, 12, 27, 0, 0, 0, 0, nyc), false},
+		// Dates are checked in the time zone of the instant.
+		{time.Date(2022, 12, 26, 3, 0, 0, 0, time.UTC).In(nyc), false},
+		{time.Date(2022, 12, 31, 12, 0, 0, 0, nyc), true},
+		{time.Date(2023, 1, 2, 12, 0, 0, 0, nyc), true},
+		{time.Date(2023, 1, 3, 0, 0, 0, 0, nyc), false},
+		{time.Date(2030, 7, 4, 9, 0, 0, 0, nyc), true},
+		{time.Date(2022, 12, 10, 7, 0, 0, 0, nyc), true},
+		{time.Date(2022, 12, 10, 9, 0, 0, 0, nyc), false},
+	}
+	for _, test := range tests {
+		if actual := cal.Excludes(test.time); actual != test.expected {
+			t.Errorf("%v: expected %v, got %v", test.time, test.expected, actual)
+		}
+	}
+}
+
+func TestExcluding(t *testing.T) {
+	cal := NewCalendar()
+	cal.AddDate(time.Date(2022, 12, 26, 0, 0, 0, 0, time.UTC))
+	cal.AddDates(time.Date(2022, 12, 27, 0, 0, 0, 0, time.UTC), time.Date(2022, 12, 28, 0, 0, 0, 0, time.UTC))
+	cal.AddPeriod(getTime("2022-12-10T12:00:00+0000"), getTime("2022-12-10T14:00:00+0000"))
+	cal.AddYearly(time.December, 25)
+
+	tests := []struct {
+		spec     string
+		from     string
+		expected string
+	}{
+		// Every weekday at 09:00, except the holiday and the freeze.
+		{"TZ=America/New_York 0 9 * * 1-5", "2022-12-23T10:00:00-0500", "2022-12-29T09:00:00-0500"},
+		{"TZ=America/New_York 0 9 * * 1-5", "2022-12-21T10:00:00-0500", "2022-12-22T09:00:00-0500"},
+
+		// An activation at midnight right after an excluded day is kept.
+		{"TZ=UTC 0 0 * * *", "2022-12-25T12:00:00+0000", "2022-12-29T00:00:00+0000"},
+
+		// Periods are skipped at once.
+		{"TZ=UTC * * * * *", "2022-12-10T11:59:30+0000", "2022-12-10T14:00:00+0000"},
+
+		// Never runs.
+		{"TZ=UTC 0 0 25 12 *", "2022-12-10T00:00:00+0000", ""},
+	}
+	for _, test := range tests {
+		sched, err := ParseStandard(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := Excluding(sched, cal).Next(getTime(test.from))
+		if expected := getTime(test.expected); !actual.Equal(expected) {
+			t.Errorf("%s from %s: expected %v, got %v", test.spec, test.from, expected, actual)
+		}
+	}
+
+	// The dates are checked in the time zone of the schedule, not the one of
+	// the activations: 21:00 in New York is already the next day in UTC.
+	holiday := NewCalendar()
+	holiday.AddDate(time.Date(2022, 12, 26, 0, 0, 0, 0, time.UTC))
+	evening, _ := ParseStandard("TZ=America/New_York 0 21 * * *")
+	next := Excluding(evening, holiday).Next(getTime("2022-12-26T03:00:00+0000"))
+	if expected := getTime("2022-12-28T02:00:00+0000"); !next.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, next)
+	}
+
+	// A constant delay counts from the end of the exclusion.
+	every := Excluding(Every(time.Hour), cal)
+	if next, expected := every.Next(getTime("2022-12-10T11:30:00+0000")), getTime("2022-12-10T15:00:00+0000"); !next.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, next)
+	}
+}
+
+func TestCalendarAddICS(t *testing.T) {
+	const ics = "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Christmas\r\n" +
+		"DTSTART;VALUE=DATE:20221225\r\n" +
+		"RRULE:FREQ=YEARLY\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:New Year\r\n" +
+		"DTSTART;VALUE=DATE:20230101\r\n" +
+		"RRULE:FREQ=YEARLY;BYMONTH=1;BYMONTHDAY=1;INTERVAL=1\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Company freeze, which has a long summary that is folded onto\r\n" +
+		"  the next line\r\n" +
+		"DTSTART;VALUE=DATE:20221227\r\n" +
+		"DTEND;VALUE=DATE:20221230\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;TZID=Europe/Paris:20221210T100000\r\n" +
+		"DTEND;TZID=Europe/Paris:20221210T120000\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20221211T100000Z\r\n" +
+		"DTEND:20221211T110000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal := NewCalendar()
+	if err := cal.AddICS(strings.NewReader(ics)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		time     string
+		expected bool
+	}{
+		{"2030-12-25T12:00:00+0000", true},
+		{"2031-01-01T12:00:00+0000", true},
+		{"2022-12-27T00:00:00+0000", true},
+		{"2022-12-29T23:00:00+0000", true},
+		{"2022-12-30T00:00:00+0000", false}, // DTEND is exclusive
+		{"2022-12-10T09:30:00+0000", true},
+		{"2022-12-10T11:00:00+0000", false},
+		{"2022-12-11T10:59:59+0000", true},
+		{"2022-12-11T11:00:00+0000", false},
+	}
+	for _, test := range tests {
+		if actual := cal.Excludes(getTime(test.time)); actual != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.time, test.expected, actual)
+		}
+	}
+
+	errors := []struct {
+		ics, err string
+	}{
+		{"BEGIN:VEVENT\nDTSTART:20221225\nRRULE:FREQ=WEEKLY\nEND:VEVENT\n", "unsupported recurrence"},
+		{"BEGIN:VEVENT\nDTSTART:20221225\nRRULE:FREQ=YEARLY;INTERVAL=2\nEND:VEVENT\n", "unsupported recurrence"},
+		{"BEGIN:VEVENT\nDTSTART:20221225\nRRULE:FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=24,25\nEND:VEVENT\n", "unsupported recurrence"},
+		{"BEGIN:VEVENT\nSUMMARY:nothing\nEND:VEVENT\n", "without DTSTART"},
+		{"BEGIN:VEVENT\nDTSTART;TZID=Nowhere/Special:20221210T100000\nEND:VEVENT\n", "bad location"},
+		{"BEGIN:VEVENT\nDTSTART:2022-12-10\nEND:VEVENT\n", "cannot parse"},
+		{"END:VEVENT\n", "without BEGIN"},
+		{"garbage\n", "invalid content line"},
+	}
+	for _, test := range errors {
+		if err := NewCalendar().AddICS(strings.NewReader(test.ics)); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: expected %q, got %v", test.ics, test.err, err)
+		}
+	}
+
+	// An invalid event leaves the calendar unchanged.
+	cal = NewCalendar()
+	err := cal.AddICS(strings.NewReader("BEGIN:VEVENT\nDTSTART:20221225\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nSUMMARY:nothing\nEND:VEVENT\n"))
+	if err == nil {
+		t.Error("expected an error")
+	}
+	if cal.Excludes(time.Date(2022, 12, 25, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expected the valid event not to be added")
+	}
+}
+
+func TestWithExclusions(t *testing.T) {
+	now := time.Date(2022, 12, 24, 12, 0, 0, 0, time.UTC)
+	cal := NewCalendar()
+	cal.AddDate(time.Date(2022, 12, 25, 0, 0, 0, 0, time.UTC))
+	cron := New(WithClock(fixedClock{now}), WithLocation(time.UTC))
+	id, _ := cron.AddFunc("0 9 * * *", func() {}, WithExclusions(cal))
+	cron.Start()
+	defer cron.Stop()
+
+	if next, expected := cron.Entry(id).Next, time.Date(2022, 12, 26, 9, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, next)
+	}
+}
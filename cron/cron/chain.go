@@ -1,6 +1,7 @@
 package cron
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"sync"
@@ -34,10 +35,19 @@ func (c Chain) Then(j Job) Job {
 	return j
 }
 
+// contextFuncJob is the Job returned by the JobWrappers in this package, so
+// that the context and error of a wrapped JobWithContext pass through them.
+type contextFuncJob func(ctx context.Context) error
+
+func (f contextFuncJob) Run() { f(context.Background()) }
+
+func (f contextFuncJob) runContext(ctx context.Context) error { return f(ctx) }
+
 // Recover panics in wrapped jobs and log them with the provided logger.
+// The panic is still reported to the Cron's observers.
 func Recover(logger Logger) JobWrapper {
 	return func(j Job) Job {
-		return FuncJob(func() {
+		return contextFuncJob(func(ctx context.Context) (runErr error) {
 			defer func() {
 				if r := recover(); r != nil {
 					const size = 64 << 10
@@ -48,9 +58,10 @@ func Recover(logger Logger) JobWrapper {
 						err = fmt.Errorf("%v", r)
 					}
 					logger.Error(err, "panic", "stack", "...\n"+string(buf))
+					runErr = &panicError{r}
 				}
 			}()
-			j.Run()
+			return runJob(ctx, j)
 		})
 	}
 }
@@ -61,14 +72,14 @@ func Recover(logger Logger) JobWrapper {
 func DelayIfStillRunning(logger Logger) JobWrapper {
 	return func(j Job) Job {
 		var mu sync.Mutex
-		return FuncJob(func() {
+		return contextFuncJob(func(ctx context.Context) error {
 			start := time.Now()
 			mu.Lock()
 			defer mu.Unlock()
 			if dur := time.Since(start); dur > time.Minute {
 				logger.Info("delay", "duration", dur)
 			}
-			j.Run()
+			return runJob(ctx, j)
 		})
 	}
 }
@@ -79,14 +90,31 @@ func SkipIfStillRunning(logger Logger) JobWrapper {
 	return func(j Job) Job {
 		var ch = make(chan struct{}, 1)
 		ch <- struct{}{}
-		return FuncJob(func() {
+		return contextFuncJob(func(ctx context.Context) error {
 			select {
 			case v := <-ch:
-				j.Run()
+				err := runJob(ctx, j)
 				ch <- v
+				return err
 			default:
 				logger.Info("skip")
+				return nil
 			}
 		})
 	}
 }
+
+// WithTimeout cancels the context of each run of the job once the timeout has
+// passed. Only jobs which take a context (see JobWithContext) can notice it;
+// other jobs are not affected. The timeout is measured on the Clock of the Cron
+// running the job, like the delays of Retry.
+func WithTimeout(timeout time.Duration) JobWrapper {
+	return func(j Job) Job {
+		return contextFuncJob(func(ctx context.Context) error {
+			clock := clockOf(ctx)
+			ctx, cancel := withDeadline(ctx, clock, clock.Now().Add(timeout))
+			defer cancel()
+			return runJob(ctx, j)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package cron
 
 import (
+	"context"
 	"io/ioutil"
 	"log"
 	"reflect"
@@ -240,3 +241,45 @@ func TestChainSkipIfStillRunning(t *testing.T) {
 	})
 
 }
+
+func TestChainWithTimeout(t *testing.T) {
+	waitForCancel := ContextJob(FuncJobWithContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	t.Run("cancels the context after the timeout", func(t *testing.T) {
+		wrappedJob := NewChain(WithTimeout(time.Millisecond)).Then(waitForCancel)
+		done := make(chan error)
+		go func() { done <- runJob(context.Background(), wrappedJob) }()
+		select {
+		case err := <-done:
+			if err != context.DeadlineExceeded {
+				t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+			}
+		case <-time.After(time.Second):
+			t.Error("expected the job to return after the timeout")
+		}
+	})
+
+	t.Run("context and error pass through the other wrappers", func(t *testing.T) {
+		wrappedJob := NewChain(
+			Recover(DiscardLogger),
+			SkipIfStillRunning(DiscardLogger),
+			DelayIfStillRunning(DiscardLogger),
+			WithTimeout(time.Millisecond),
+		).Then(waitForCancel)
+		if err := runJob(context.Background(), wrappedJob); err != context.DeadlineExceeded {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("plain jobs are not affected", func(t *testing.T) {
+		var j countJob
+		j.delay = 5 * time.Millisecond
+		wrappedJob := NewChain(WithTimeout(time.Millisecond)).Then(&j)
+		if err := runJob(context.Background(), wrappedJob); err != nil || j.Done() != 1 {
+			t.Errorf("expected the job to complete, got %v, %d", err, j.Done())
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package cron
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultClock is the clock used by a Cron unless one is set with WithClock.
+// It uses the system clock.
+var DefaultClock Clock = systemClock{}
+
+// Clock is the source of time of a Cron. Tests may replace it with a fake
+// clock, such as the one of the crontest package, to control the time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer returns a Timer which fires once d has elapsed.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer created by a Clock, like time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered when the timer
+	// fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+}
+
+// Tracker may be implemented by a Clock which needs to follow the work of the
+// Cron, such as a fake clock firing the due entries synchronously.
+type Tracker interface {
+	// Track is called by WithClock with a func which returns once the Cron
+	// has handled the calls made before it, and waits for its timer.
+	Track(sync func())
+
+	// Go runs f, the run of a job, in a new goroutine.
+	Go(f func())
+}
+
+// Sleeper may be implemented by a Clock to let jobs wait on it, as Retry does.
+// A fake clock needs it to tell the jobs waiting for its time to pass from the
+// running ones.
+type Sleeper interface {
+	// Sleep waits until d has elapsed or ctx is done. It reports whether d
+	// elapsed.
+	Sleep(ctx context.Context, d time.Duration) bool
+
+	// WithDeadline returns a copy of ctx which is cancelled once the clock
+	// reaches deadline, like context.WithDeadline.
+	WithDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc)
+}
+
+// clockKey is the context key of the Clock of the Cron running a job.
+type clockKey struct{}
+
+// clockOf returns the Clock of the Cron running the job given the context of
+// the run, or DefaultClock.
+func clockOf(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+	return DefaultClock
+}
+
+// sleep waits on the clock until d has elapsed or ctx is done, see Sleeper.
+func sleep(ctx context.Context, clock Clock, d time.Duration) bool {
+	if s, ok := clock.(Sleeper); ok {
+		return s.Sleep(ctx, d)
+	}
+	timer := clock.NewTimer(d)
+	select {
+	case <-timer.C():
+		return true
+	case <-ctx.Done():
+		timer.Stop()
+		return false
+	}
+}
+
+// withDeadline is context.WithDeadline on the time of the clock, see Sleeper.
+func withDeadline(ctx context.Context, clock Clock, deadline time.Time) (context.Context, context.CancelFunc) {
+	if s, ok := clock.(Sleeper); ok {
+		return s.WithDeadline(ctx, deadline)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		if sleep(ctx, clock, deadline.Sub(clock.Now())) {
+			cancel()
+		}
+	}()
+	return ctx, cancel
+}
+
+// systemClock implements DefaultClock with the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (systemClock) WithDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(ctx, deadline)
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// goJob runs the job's goroutine, through the Tracker if the clock is one.
+func (c *Cron) goJob(f func()) {
+	if t, ok := c.clock.(Tracker); ok {
+		t.Go(f)
+		return
+	}
+	go f()
+}
@@ -0,0 +1,39 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// fixedClock is stuck at the given time, but its timers are real.
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func (fixedClock) NewTimer(d time.Duration) Timer { return DefaultClock.NewTimer(d) }
+
+func TestWithClock(t *testing.T) {
+	now := time.Date(2022, 12, 10, 12, 30, 0, 0, time.UTC)
+	cron := New(WithClock(fixedClock{now}), WithLocation(time.UTC))
+	id, _ := cron.AddFunc("@hourly", func() {})
+	cron.Start()
+	defer cron.Stop()
+
+	if next, expected := cron.Entry(id).Next, now.Add(30*time.Minute); !next.Equal(expected) {
+		t.Errorf("expected the next run at %v, got %v", expected, next)
+	}
+}
+
+func TestSystemClock(t *testing.T) {
+	timer := DefaultClock.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(OneSecond):
+		t.Fatal("expected the timer to fire")
+	}
+	if timer.Stop() {
+		t.Error("expected the timer to have fired already")
+	}
+}
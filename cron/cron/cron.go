@@ -2,7 +2,10 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -12,22 +15,42 @@ import (
 // be inspected while running.
 type Cron struct {
 	entries   []*Entry // 全部 cron-job 都会在这里
+	done      []*Entry // Schedule 已经用完、自动移除的 entry，只保留最近的 maxFinishedEntries 个
 	chain     Chain
 	stop      chan struct{}
 
 	// cron.Cron.Start() 之后，必须通过 channel 新增 cron-job
 	add       chan *Entry
 	// cron.Cron.Start() 之后，必须通过 channel 删除 cron-job
-	remove    chan EntryID
+	remove chan EntryID
+	// 同理，暂停、恢复、立即触发 cron-job 也要通过 channel
+	pause   chan EntryID
+	resume  chan EntryID
+	trigger chan EntryID
+	// 依赖关系的修改，以及 job 结束的通知，同样交给 run() 的 goroutine 处理
+	depend    chan dependRequest
+	results   chan runResult
 	snapshot  chan chan []Entry
 	running   bool
 	logger    Logger
 	runningMu sync.Mutex
 	location  *time.Location
+	clock     Clock          // 时间来源，测试时可以换成 crontest.FakeClock
 	parser    ScheduleParser // 如何解析时间描述字符串
 	nextID    EntryID
 	// 确保退出时，正在运行的 cron-job 能够完成。而不是做了一半，就直接退出了
 	jobWaiter sync.WaitGroup
+	store     JobStore // 持久化 entry 的 Prev/Next，重启后用来补跑错过的 job
+	locker    Locker   // 多个副本跑同一份 schedule 时，只有拿到 lease 的那个才执行 job
+	// 每次 run() 都会新建一个 context，Stop() 时 cancel 掉，通知正在运行的 JobWithContext 退出
+	jobCtx       context.Context
+	cancelJobs   context.CancelFunc
+	errorHandler func(EntryID, error)
+	observers    []Observer
+	historySize  int // 每个 entry 保留最近多少次运行记录
+	// 限制同时运行的 job 数量：全局一个，另外每个 group 各一个
+	limit  *limiter
+	groups map[string]*limiter
 }
 
 // ScheduleParser is an interface for schedule spec parsers that return a Schedule
@@ -37,11 +60,27 @@ type ScheduleParser interface {
 	Parse(spec string) (Schedule, error)
 }
 
+// hashParser is implemented by parsers supporting H tokens, such as Parser.
+// The Cron passes them the key of the entry being added.
+type hashParser interface {
+	ParseHashed(spec, key string) (Schedule, error)
+}
+
 // Job is an interface for submitted cron jobs.
 type Job interface {
 	Run()
 }
 
+// JobWithContext is an interface for submitted cron jobs which can be told to
+// give up and which report failure. The context is cancelled when the Cron is
+// stopped, or when a timeout set with WithTimeout expires. A returned error is
+// logged and passed to the error handler (see WithErrorHandler).
+//
+// Use ContextJob to submit it where a Job is expected.
+type JobWithContext interface {
+	Run(ctx context.Context) error
+}
+
 // Schedule describes a job's duty cycle.
 // Schedule interface de 唯一能力是：告诉调用者，
 // 下一个时刻是什么时候，然后调用者根据这个触发时刻对不同的 job 进行排序
@@ -60,11 +99,25 @@ type Entry struct {
 	// snapshot or remove it.
 	ID EntryID
 
+	// Name optionally identifies the entry independently of its ID. Unlike the
+	// ID, it stays the same across process restarts, so a JobStore uses it to
+	// match saved state with the re-registered entry. It must be unique among
+	// the entries of the Cron, see ErrDuplicateName.
+	Name string
+
 	// Schedule on which this job should be run.
 	// 回答 cron.Cron 下一次触发的时间点是？
 	// 用来生成下一次运行的时间
 	Schedule Schedule
 
+	// Spec is the spec string the Schedule was parsed from, or empty if the
+	// entry was added with a Schedule directly.
+	Spec string
+
+	// MisfirePolicy decides what happens to the runs this entry missed while
+	// the Cron was not running. It only applies when a JobStore is configured.
+	MisfirePolicy MisfirePolicy
+
 	// Next time the job will run, or the zero time if Cron has not been
 	// started or this entry's schedule is unsatisfiable
 	// 总是通过 Entry.Schedule.Next() 来更新
@@ -73,6 +126,15 @@ type Entry struct {
 	// Prev is the last time this job was run, or the zero time if never.
 	Prev time.Time
 
+	// Paused is true while the entry is paused, see Cron.Pause. A paused
+	// entry has a zero Next.
+	Paused bool
+
+	// Finished is the time the entry's schedule ran out of activations (see
+	// At, Times and Between), or the zero time if it has not. The Cron then
+	// removes the entry, but keeps reporting it in Entries.
+	Finished time.Time
+
 	// WrappedJob is the thing to run when the Schedule is activated.
 	// Q&A(DONE): 这样 wrapped 起来的目的是什么？
 	// 链式调用，
@@ -83,11 +145,46 @@ type Entry struct {
 	// It is kept around so that user code that needs to get at the job later,
 	// e.g. via Entries() can do so.
 	Job Job
+
+	// history keeps the most recent runs, see History().
+	history *runHistory
+
+	// Group is the concurrency group the entry's runs count against, if any.
+	// See WithConcurrencyGroup.
+	Group string
+
+	// jitter is the window of the Jitter applied to the Schedule, see
+	// WithJitter.
+	jitter time.Duration
+
+	// calendar excludes activations of the Schedule, see WithExclusions.
+	calendar *Calendar
+
+	// succeeded holds the dependencies which succeeded since the entry last
+	// ran, and have not failed since, see Cron.AddDependency.
+	succeeded map[EntryID]bool
+
+	// runs counts the activations of the Schedule which were run, see Times.
+	runs int
 }
 
 // Valid returns true if this is not the zero entry.
 func (e Entry) Valid() bool { return e.ID != 0 }
 
+// ErrDuplicateName is returned when adding an entry whose Name, or ID for an
+// entry without a name, identifies an entry of the Cron already: the two would
+// share their leases, stored state, H values and jitter.
+var ErrDuplicateName = errors.New("duplicate entry name")
+
+// key identifies the entry in a JobStore: its Name if it has one, otherwise
+// its ID.
+func (e Entry) key() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return strconv.Itoa(int(e.ID))
+}
+
 // byTime is a wrapper for sorting the entry array by time
 // (with zero time at the end).
 type byTime []*Entry
@@ -123,6 +220,26 @@ func (s byTime) Less(i, j int) bool {
 //     Description: Wrap submitted jobs to customize behavior.
 //     Default:     A chain that recovers panics and logs them to stderr.
 //
+//   JobStore
+//     Description: Persists entries so missed runs can be caught up after a restart.
+//     Default:     None, entries are only kept in memory.
+//
+//   Locker
+//     Description: Leases each activation, so only one of several replicas runs it.
+//     Default:     None, every activation runs.
+//
+//   History
+//     Description: Number of recent runs kept per entry, see Entry.History.
+//     Default:     DefaultHistorySize
+//
+//   Clock
+//     Description: The source of time, see crontest.FakeClock for tests.
+//     Default:     DefaultClock
+//
+//   Concurrency
+//     Description: Limits the number of jobs running at once, overall and per group.
+//     Default:     None, every run starts right away.
+//
 // See "cron.With*" to modify the default behavior.
 // 通过注入可变参数 Option 的方式来进行初始化，Option 很显然会是一个函数变量，专门用来修改刚刚生成的 Cron 中的部分参数
 func New(opts ...Option) *Cron {
@@ -133,11 +250,19 @@ func New(opts ...Option) *Cron {
 		stop:      make(chan struct{}),
 		snapshot:  make(chan chan []Entry),
 		remove:    make(chan EntryID),
+		pause:     make(chan EntryID),
+		resume:    make(chan EntryID),
+		trigger:   make(chan EntryID),
+		depend:    make(chan dependRequest),
+		results:   make(chan runResult),
 		running:   false,
 		runningMu: sync.Mutex{},
 		logger:    DefaultLogger,
 		location:  time.Local,
+		clock:     DefaultClock,
 		parser:    standardParser,
+
+		historySize: DefaultHistorySize,
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -150,42 +275,132 @@ type FuncJob func()
 
 func (f FuncJob) Run() { f() }
 
+// FuncJobWithContext is a wrapper that turns a func(context.Context) error
+// into a cron.JobWithContext
+type FuncJobWithContext func(ctx context.Context) error
+
+func (f FuncJobWithContext) Run(ctx context.Context) error { return f(ctx) }
+
+// contextRunner is implemented by jobs which take the context of the run and
+// report its error: the Jobs returned by ContextJob, and those returned by the
+// JobWrappers in this package as long as they wrap one.
+type contextRunner interface {
+	runContext(ctx context.Context) error
+}
+
+// contextJob is the Job returned by ContextJob.
+type contextJob struct {
+	job JobWithContext
+}
+
+// Run runs the job outside of a Cron, which has no context to give it.
+func (j contextJob) Run() { j.job.Run(context.Background()) }
+
+func (j contextJob) runContext(ctx context.Context) error { return j.job.Run(ctx) }
+
+// ContextJob turns a JobWithContext into a Job, so that it can be submitted
+// and wrapped like any other Job. When run by a Cron, it gets the context of
+// the run and its error is reported. Note that JobWrappers from outside this
+// package do not pass the context on: a job wrapped in one of them is run with
+// context.Background() and its error is dropped.
+func ContextJob(j JobWithContext) Job {
+	return contextJob{j}
+}
+
+// runJob runs the job with the given context, if it takes one, and returns
+// its error.
+func runJob(ctx context.Context, j Job) error {
+	if cr, ok := j.(contextRunner); ok {
+		return cr.runContext(ctx)
+	}
+	j.Run()
+	return nil
+}
+
 // AddFunc adds a func to the Cron to be run on the given schedule.
 // The spec is parsed using the time zone of this Cron instance as the default.
 // An opaque ID is returned that can be used to later remove it.
 // 最后都是使用 type FuncJob func() 将 cmd 传递给 cron.AddJob()
-func (c *Cron) AddFunc(spec string, cmd func()) (EntryID, error) {
-	return c.AddJob(spec, FuncJob(cmd))
+func (c *Cron) AddFunc(spec string, cmd func(), opts ...EntryOption) (EntryID, error) {
+	return c.AddJob(spec, FuncJob(cmd), opts...)
+}
+
+// AddFuncWithContext adds a func which takes a context to the Cron to be run
+// on the given schedule. See JobWithContext.
+func (c *Cron) AddFuncWithContext(spec string, cmd func(ctx context.Context) error, opts ...EntryOption) (EntryID, error) {
+	return c.AddJob(spec, ContextJob(FuncJobWithContext(cmd)), opts...)
+}
+
+// AddJobWithContext adds a JobWithContext to the Cron to be run on the given
+// schedule.
+func (c *Cron) AddJobWithContext(spec string, cmd JobWithContext, opts ...EntryOption) (EntryID, error) {
+	return c.AddJob(spec, ContextJob(cmd), opts...)
 }
 
 // AddJob adds a Job to the Cron to be run on the given schedule.
 // The spec is parsed using the time zone of this Cron instance as the default.
 // An opaque ID is returned that can be used to later remove it.
-func (c *Cron) AddJob(spec string, cmd Job) (EntryID, error) {
+func (c *Cron) AddJob(spec string, cmd Job, opts ...EntryOption) (EntryID, error) {
 	// cron.WithSecond() 的话，就是调用：cron.Parser.Parse()
 	// 然后将时间描述字符串解析为由 cron.SpecSchedule struct impl 的 Schedule interface
 	// 这样 cron.Cron 能够通过 SpecSchedule.Next() 来询问这个 job 下一次触发是什么时候，
 	// 从而将所有的定时 job 进行排序
-	schedule, err := c.parser.Parse(spec)
-	if err != nil {
-		return 0, err
+	return c.schedule(spec, func(key string) (Schedule, error) {
+		return c.parse(spec, key)
+	}, cmd, opts)
+}
+
+// parse parses the spec of the entry identified by key.
+func (c *Cron) parse(spec, key string) (Schedule, error) {
+	if p, ok := c.parser.(hashParser); ok {
+		return p.ParseHashed(spec, key)
 	}
-	return c.Schedule(schedule, cmd), nil
+	return c.parser.Parse(spec)
 }
 
 // Schedule adds a Job to the Cron to be run on the given schedule.
-// The job is wrapped with the configured Chain.
+// The job is wrapped with the configured Chain. If the entry's name is already
+// in use, the error is logged and the returned ID is 0, which identifies no
+// entry.
 // 对与 cron.Cron 而言，只需要只需要直到一个 Job 的两个特点：下次触发是什么时候 + 触发时要干什么
-func (c *Cron) Schedule(schedule Schedule, cmd Job) EntryID {
+func (c *Cron) Schedule(schedule Schedule, cmd Job, opts ...EntryOption) EntryID {
+	id, err := c.schedule("", func(string) (Schedule, error) { return schedule, nil }, cmd, opts)
+	if err != nil {
+		c.logger.Error(err, "schedule")
+	}
+	return id
+}
+
+// schedule adds the entry, remembering the spec it was parsed from (if any).
+// The schedule is only built once the entry's key is known, since H tokens
+// and the jitter depend on it.
+func (c *Cron) schedule(spec string, build func(key string) (Schedule, error), cmd Job, opts []EntryOption) (EntryID, error) {
 	c.runningMu.Lock()
 	defer c.runningMu.Unlock()
-	c.nextID++
 	entry := &Entry{
-		ID:         c.nextID,
-		Schedule:   schedule, // Schedule interface 的核心是：告诉 cron.Cron，自己这个 entry 下一个被激活的时刻是？
+		ID:         c.nextID + 1,
+		Spec:       spec,
 		WrappedJob: c.chain.Then(cmd),
 		Job:        cmd,
+		history:    newRunHistory(c.historySize),
+	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+	// key 是 entry 跨重启的身份，两个 entry 共用的话 lease、JobStore 记录之类的都会混在一起
+	if c.keyInUse(entry.key()) {
+		return 0, fmt.Errorf("%w: %q", ErrDuplicateName, entry.key())
+	}
+	schedule, err := build(entry.key())
+	if err != nil {
+		return 0, err
 	}
+	// Schedule interface 的核心是：告诉 cron.Cron，自己这个 entry 下一个被激活的时刻是？
+	entry.Schedule = Jitter(schedule, entry.key(), entry.jitter)
+	if entry.calendar != nil {
+		entry.Schedule = Excluding(entry.Schedule, entry.calendar)
+	}
+	c.nextID++
 
 	// c.running 是在 cron.Start() 的时候被 set 的
 	// TODO: 为什么 running 前后是使用不同的 append 方式，为什么要这么做？
@@ -197,7 +412,7 @@ func (c *Cron) Schedule(schedule Schedule, cmd Job) EntryID {
 		// 而且这是一个 unbuffered channel，会把接收跟发送者都 block 住
 		c.add <- entry
 	}
-	return entry.ID
+	return entry.ID, nil
 }
 
 // Entries returns a snapshot of the cron entries.
@@ -239,6 +454,43 @@ func (c *Cron) Remove(id EntryID) {
 	}
 }
 
+// Pause stops the entry from being run, until it is resumed. The entry keeps
+// its ID and wrapped job. Runs which already started are not affected.
+func (c *Cron) Pause(id EntryID) {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+	if c.running {
+		c.pause <- id
+	} else {
+		c.pauseEntry(id)
+	}
+}
+
+// Resume schedules a paused entry again, from its next activation time after
+// now. Activations missed while the entry was paused are not run.
+func (c *Cron) Resume(id EntryID) {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+	if c.running {
+		c.resume <- id
+	} else if e := c.entry(id); e != nil {
+		e.Paused = false // Next 会在 run() 启动时统一计算
+	}
+}
+
+// RunNow runs the entry's job immediately, in addition to its scheduled runs,
+// and even if the entry is paused. It does not change the entry's Prev or
+// Next. If the Cron is not running, the job gets a background context.
+func (c *Cron) RunNow(id EntryID) {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+	if c.running {
+		c.trigger <- id
+	} else if e := c.entry(id); e != nil {
+		c.startJobContext(context.Background(), e, c.now())
+	}
+}
+
 // Start the cron scheduler in its own goroutine, or no-op if already started.
 // 创建一个新的 goroutine 来监控定期任务的执行
 func (c *Cron) Start() {
@@ -271,14 +523,28 @@ func (c *Cron) Run() {
 // 总是被 c.runningMu.Lock() 保护着
 func (c *Cron) run() {
 	c.logger.Info("start")
+	c.jobCtx, c.cancelJobs = context.WithCancel(context.Background())
 
 	// Figure out the next activation times for each entry.
 	/* 确认当前时间，以及每一个 entry 下一次激活的时间 */
 	now := c.now() // 获取当前时间
+
+	stored := c.loadEntries() // 没有配置 JobStore 的话是空的
 	for _, entry := range c.entries {
+		if s, ok := stored[entry.key()]; ok {
+			entry.Paused = entry.Paused || s.Paused // 重启之前被暂停的 entry 继续保持暂停
+			c.catchUp(entry, s, now)                // 恢复 Prev，并按 MisfirePolicy 补跑停机期间错过的 job
+		}
+		if entry.Paused {
+			c.logger.Info("paused", "entry", entry.ID)
+			continue
+		}
 		entry.Next = entry.Schedule.Next(now) // update 每一个 entry 下一次执行的时间点
 		c.logger.Info("schedule", "now", now, "entry", entry.ID, "next", entry.Next)
+		c.scheduled(entry)
 	}
+	c.finishExhausted(now) // 补跑用完了 Times 限制的 entry，直接结束
+	c.saveEntries()
 
 	/* 无限循环 to handler cron-job */
 	// 只有当退出信号到来，才会退出这个循环
@@ -294,24 +560,27 @@ func (c *Cron) run() {
 	//   5. 删除 cron-job(case id := <-c.remove:)
 	// }
 	for {
+		// Schedule 用完的 entry 自动移除，不会带着零值的 Next 一直留在 entries 里
+		c.finishExhausted(now)
+
 		// Determine the next entry to run.
 		// 把所有定时任务，从最近到最远的顺序排列，并把顺序存储在 cron.Cron.entries 中
 		sort.Sort(byTime(c.entries))
 
-		var timer *time.Timer
+		var timer Timer
 		if len(c.entries) == 0 || c.entries[0].Next.IsZero() {
 			// If there are no entries yet, just sleep - it still handles new entries
 			// and stop requests.
-			timer = time.NewTimer(100000 * time.Hour)
+			timer = c.clock.NewTimer(100000 * time.Hour)
 		} else {
 			// 创建一个 timer，并设置为最先被激活的任务的时间差
 			// now - next-job-active-time
-			timer = time.NewTimer(c.entries[0].Next.Sub(now))
+			timer = c.clock.NewTimer(c.entries[0].Next.Sub(now))
 		}
 
 		for {
 			select {
-			case now = <-timer.C:
+			case now = <-timer.C():
 				// 1. 最近的 cron-job 需要被激活(case now = <-timer.C:)
 				now = now.In(c.location)
 				c.logger.Info("wake", "now", now)
@@ -323,11 +592,14 @@ func (c *Cron) run() {
 					if e.Next.After(now) || e.Next.IsZero() {
 						break
 					}
-					c.startJob(e.WrappedJob)
 					e.Prev = e.Next
+					e.runs++
 					e.Next = e.Schedule.Next(now) // 下一次 for-loop round 重新排序
+					c.startJob(e, e.Prev)         // 先算好 Next，job 才能知道下一次触发的时间
 					c.logger.Info("run", "now", now, "entry", e.ID, "next", e.Next)
+					c.scheduled(e)
 				}
+				c.saveEntries()
 
 			case newEntry := <-c.add:
 				// 2. 新增 cron-job(case newEntry := <-c.add:)
@@ -336,6 +608,8 @@ func (c *Cron) run() {
 				newEntry.Next = newEntry.Schedule.Next(now)
 				c.entries = append(c.entries, newEntry) // 安全，因为由 c.runningMu.Lock() 保护
 				c.logger.Info("added", "now", now, "entry", newEntry.ID, "next", newEntry.Next)
+				c.scheduled(newEntry)
+				c.saveEntries()
 
 			case replyChan := <-c.snapshot:
 				// 3. 相应 Entries() 的 deep copy 请求()
@@ -345,6 +619,7 @@ func (c *Cron) run() {
 			case <-c.stop:
 				// 4. 退出信号(casa <-c.stop:)
 				timer.Stop()
+				c.cancelJobs() // 通知正在运行的 job 退出，Stop() 返回的 context 仍然会等它们结束
 				c.logger.Info("stop")
 				return
 
@@ -354,6 +629,42 @@ func (c *Cron) run() {
 				now = c.now() // for next for-loop round, 因为 timer 要重新设定了
 				c.removeEntry(id) // 遍历，O(n) 复杂度
 				c.logger.Info("removed", "entry", id)
+				c.saveEntries()
+
+			case id := <-c.pause:
+				// 6. 暂停 cron-job，Next 置零之后就会排到最后，不会被触发
+				timer.Stop()
+				now = c.now()
+				c.pauseEntry(id)
+				c.saveEntries()
+
+			case id := <-c.resume:
+				// 7. 恢复 cron-job，根据 Schedule 重新计算 Next
+				timer.Stop()
+				now = c.now()
+				c.resumeEntry(id, now)
+				c.saveEntries()
+
+			case req := <-c.depend:
+				// 9. 修改依赖关系，依赖其他 entry 的 entry 不再按自己的 Schedule 触发
+				timer.Stop()
+				now = c.now()
+				req.err <- c.addDependencies(req.id, req.deps)
+				c.saveEntries()
+
+			case res := <-c.results:
+				// 10. job 结束，成功的话触发依赖它的 entry；Next 不变，timer 不需要重新设定
+				c.runDependents(res, c.now())
+				c.saveEntries()
+				continue
+
+			case id := <-c.trigger:
+				// 8. 立即触发 cron-job，不影响 Next，所以 timer 不需要重新设定
+				if e := c.entry(id); e != nil {
+					c.startJob(e, c.now())
+					c.logger.Info("run now", "entry", id)
+				}
+				continue
 			}
 
 			break // 必定 break，其实这一层 for-loop 就没啥必要了
@@ -361,22 +672,57 @@ func (c *Cron) run() {
 	}
 }
 
-// startJob runs the given job in a new goroutine.
+// startJob runs the given entry's job in a new goroutine, for its activation
+// at the scheduled time.
 // 利用 sync.WaitGroup 来确保退出时，正在运行的 cron-job 能够全部执行完成
-func (c *Cron) startJob(j Job) {
+func (c *Cron) startJob(e *Entry, scheduled time.Time) {
+	c.startJobContext(c.jobCtx, e, scheduled)
+}
+
+// startJobContext is startJob with the given context for the job.
+func (c *Cron) startJobContext(ctx context.Context, e *Entry, scheduled time.Time) {
 	c.jobWaiter.Add(1)
-	go func() {
+	// entry 只能在 run() 的 goroutine 中访问，所以先把需要的字段拷贝出来
+	key, group, j, h := e.key(), e.Group, e.WrappedJob, e.history
+	notify := len(c.dependents(e.ID)) > 0 // 有 entry 依赖它的话，结束之后要通知 run()
+	ev := JobEvent{Entry: e.ID, Name: e.Name, Scheduled: scheduled}
+	ctx = context.WithValue(ctx, clockKey{}, c.clock)
+	if e.Next.After(scheduled) {
+		ctx = context.WithValue(ctx, nextActivationKey{}, e.Next)
+	}
+	c.goJob(func() {
 		defer c.jobWaiter.Done()
-		j.Run() // 调用相应的 callback
-	}()
+		release, ok := c.acquireSlots(ctx, ev.Entry, group)
+		if !ok {
+			return
+		}
+		defer release()
+		if !c.acquireLease(ev.Entry, key, scheduled) {
+			return
+		}
+		succeeded := c.execute(ctx, j, h, ev) // 调用相应的 callback
+		if notify {
+			c.jobFinished(runResult{ev.Entry, succeeded})
+		}
+	})
+}
+
+// handleError logs the error returned by the entry's job and passes it to the
+// error handler, if there is one.
+func (c *Cron) handleError(id EntryID, err error) {
+	c.logger.Error(err, "failed", "entry", id)
+	if c.errorHandler != nil {
+		c.errorHandler(id, err)
+	}
 }
 
 // now returns current time in c location
 func (c *Cron) now() time.Time {
-	return time.Now().In(c.location)
+	return c.clock.Now().In(c.location)
 }
 
 // Stop stops the cron scheduler if it is running; otherwise it does nothing.
+// The context given to running jobs (see JobWithContext) is cancelled.
 // A context is returned so the caller can wait for running jobs to complete.
 func (c *Cron) Stop() context.Context {
 	c.runningMu.Lock()
@@ -393,15 +739,70 @@ func (c *Cron) Stop() context.Context {
 	return ctx
 }
 
+// keyInUse reports whether an entry which has not finished has the key. The
+// caller holds runningMu.
+func (c *Cron) keyInUse(key string) bool {
+	var entries []Entry
+	if c.running {
+		replyChan := make(chan []Entry, 1)
+		c.snapshot <- replyChan
+		entries = <-replyChan
+	} else {
+		entries = c.entrySnapshot()
+	}
+	for _, e := range entries {
+		if e.Finished.IsZero() && e.key() == key {
+			return true
+		}
+	}
+	return false
+}
+
 // entrySnapshot returns a copy of the current cron entry list.(deep copy)
+// The finished entries come last.
 func (c *Cron) entrySnapshot() []Entry {
-	var entries = make([]Entry, len(c.entries))
-	for i, e := range c.entries {
-		entries[i] = *e
+	var entries = make([]Entry, 0, len(c.entries)+len(c.done))
+	for _, e := range c.entries {
+		entries = append(entries, *e)
+	}
+	for _, e := range c.done {
+		entries = append(entries, *e)
 	}
 	return entries
 }
 
+// entry returns the entry with the given ID, or nil if there is none.
+func (c *Cron) entry(id EntryID) *Entry {
+	for _, e := range c.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// pauseEntry marks the entry as paused and clears its next activation.
+func (c *Cron) pauseEntry(id EntryID) {
+	if e := c.entry(id); e != nil {
+		e.Paused = true
+		e.Next = time.Time{}
+		c.logger.Info("paused", "entry", id)
+	}
+}
+
+// resumeEntry schedules a paused entry again, from the given time. It is only
+// called by the running scheduler.
+func (c *Cron) resumeEntry(id EntryID, now time.Time) {
+	e := c.entry(id)
+	if e == nil || !e.Paused {
+		return
+	}
+	e.Paused = false
+	e.Next = e.Schedule.Next(now)
+	c.logger.Info("resumed", "now", now, "entry", id, "next", e.Next)
+	c.scheduled(e)
+}
+
 // 直接创建新的 entries
 func (c *Cron) removeEntry(id EntryID) {
 	var entries []*Entry
@@ -411,4 +812,13 @@ func (c *Cron) removeEntry(id EntryID) {
 		}
 	}
 	c.entries = entries
+	c.dropDependency(id, c.now())
+
+	var done []*Entry
+	for _, e := range c.done {
+		if e.ID != id {
+			done = append(done, e)
+		}
+	}
+	c.done = done
 }
@@ -2,6 +2,8 @@ package cron
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -678,6 +680,141 @@ func TestMultiThreadedStartAndStop(t *testing.T) {
 	cron.Stop()
 }
 
+// Test that the context of running jobs is cancelled on Stop.
+func TestJobWithContextCancelledOnStop(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	cron := newWithSeconds()
+	cron.AddFuncWithContext("* * * * * ?", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return nil
+	})
+	cron.Start()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected job runs")
+	case <-started:
+	}
+
+	ctx := cron.Stop()
+	select {
+	case <-time.After(time.Second):
+		t.Fatal("expected the job's context to be cancelled")
+	case <-cancelled:
+	}
+	select {
+	case <-time.After(time.Second):
+		t.Error("expected Stop's context to be done once the job returned")
+	case <-ctx.Done():
+	}
+}
+
+// Test that errors returned by jobs reach the logger and the error handler.
+func TestJobWithContextError(t *testing.T) {
+	var buf syncWriter
+	errs := make(chan error, 1)
+	var handledID EntryID
+
+	cron := New(WithParser(secondParser), WithChain(), WithLogger(newBufLogger(&buf)),
+		WithErrorHandler(func(id EntryID, err error) {
+			handledID = id
+			errs <- err
+		}))
+	id, _ := cron.AddJobWithContext("* * * * * ?", FuncJobWithContext(func(context.Context) error {
+		return fmt.Errorf("YOLO")
+	}))
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected the error handler to be called")
+	case err := <-errs:
+		if err.Error() != "YOLO" || handledID != id {
+			t.Errorf("expected YOLO from entry %d, got %v from entry %d", id, err, handledID)
+		}
+	}
+	if !strings.Contains(buf.String(), "YOLO") {
+		t.Error("expected the error to be logged, got none")
+	}
+}
+
+// Start cron, add a job, pause it, expect it doesn't run.
+func TestPauseWhileRunning(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron := newWithSeconds()
+	cron.Start()
+	defer cron.Stop()
+	id, _ := cron.AddFunc("* * * * * ?", func() { wg.Done() })
+	cron.Pause(id)
+
+	if entry := cron.Entry(id); !entry.Paused || !entry.Next.IsZero() {
+		t.Errorf("expected a paused entry without Next, got %+v", entry)
+	}
+	select {
+	case <-time.After(OneSecond):
+	case <-wait(wg):
+		t.FailNow()
+	}
+}
+
+// Add a job, pause it, start cron, resume it, expect it runs.
+func TestResume(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron := newWithSeconds()
+	id, _ := cron.AddFunc("* * * * * ?", func() { wg.Done() })
+	cron.Pause(id)
+	cron.Start()
+	defer cron.Stop()
+
+	if entry := cron.Entry(id); !entry.Paused || !entry.Next.IsZero() {
+		t.Errorf("expected a paused entry without Next, got %+v", entry)
+	}
+	cron.Resume(id)
+	if entry := cron.Entry(id); entry.Paused || entry.Next.IsZero() {
+		t.Errorf("expected a scheduled entry, got %+v", entry)
+	}
+
+	select {
+	case <-time.After(OneSecond):
+		t.Error("expected job runs")
+	case <-wait(wg):
+	}
+}
+
+// Start cron, add a job scheduled far in the future, run it now.
+func TestRunNow(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	cron := newWithSeconds()
+	id, _ := cron.AddFunc("0 0 0 1 1 ?", func() { wg.Done() })
+	cron.RunNow(id) // not running yet
+	cron.Start()
+	defer cron.Stop()
+	next := cron.Entry(id).Next
+	cron.Pause(id)
+	cron.RunNow(id) // paused
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected job runs twice")
+	case <-wait(wg):
+	}
+	cron.Resume(id)
+	if entry := cron.Entry(id); !entry.Next.Equal(next) || !entry.Prev.IsZero() {
+		t.Errorf("expected Next %v and zero Prev, got %+v", next, entry)
+	}
+}
+
 func wait(wg *sync.WaitGroup) chan bool {
 	ch := make(chan bool)
 	go func() {
@@ -700,3 +837,34 @@ func stop(cron *Cron) chan bool {
 func newWithSeconds() *Cron {
 	return New(WithParser(secondParser), WithChain())
 }
+
+func TestDuplicateName(t *testing.T) {
+	cron := New(WithLogger(DiscardLogger))
+	id, err := cron.AddFunc("@hourly", func() {}, WithName("backup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cron.AddFunc("@daily", func() {}, WithName("backup")); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("expected a duplicate name error, got %v", err)
+	}
+	// An unnamed entry is identified by its ID
+	if _, err := cron.AddFunc("@daily", func() {}, WithName("3")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cron.AddFunc("@daily", func() {}); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("expected a duplicate name error for ID 3, got %v", err)
+	}
+
+	cron.Start()
+	defer cron.Stop()
+	if id := cron.Schedule(Every(time.Hour), FuncJob(func() {}), WithName("backup")); id != 0 {
+		t.Errorf("expected no entry while running, got %d", id)
+	}
+	cron.Remove(id)
+	if _, err := cron.AddFunc("@daily", func() {}, WithName("backup")); err != nil {
+		t.Errorf("expected the name to be free once removed, got %v", err)
+	}
+	if n := len(cron.Entries()); n != 2 {
+		t.Errorf("expected 2 entries, got %d", n)
+	}
+}
@@ -0,0 +1,325 @@
+// Package crontest provides a fake clock to test code driven by a cron.Cron
+// without waiting for real time to pass.
+//
+//	clock := crontest.NewFakeClock(time.Date(2022, 12, 31, 23, 0, 0, 0, time.UTC))
+//	c := cron.New(cron.WithClock(clock), cron.WithLocation(time.UTC))
+//	c.AddFunc("@yearly", happyNewYear)
+//	c.Start()
+//	defer c.Stop()
+//
+//	clock.Advance(time.Hour) // happyNewYear has run when Advance returns
+package crontest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"main/cron"
+)
+
+// FakeClock is a cron.Clock whose time only moves when told to. Advancing it
+// fires the timers of the Crons using it, so that the entries due by the new
+// time run, in order, before Advance returns.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	wake   chan struct{} // closed and replaced whenever a timer is created or stopped
+	syncs  []func()
+
+	// 正在跑的 job 数量，其中在 Sleep() 里面等时间过去的数量，以及还没到期的 WithDeadline() 数量
+	running  int
+	sleeping int
+	waiting  int
+	changes  int        // 上面三个每变一次就加一
+	idle     *sync.Cond // 上面三个变了就 Broadcast
+}
+
+// settle is how long the jobs holding a pending deadline must stay still before
+// Advance considers them to be waiting for it.
+const settle = 10 * time.Millisecond
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now, wake: make(chan struct{})}
+	c.idle = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer firing once the clock is advanced by d or more.
+// Unlike a real timer, a timer with a negative or zero duration only fires on
+// the next call to Advance, which may be Advance(0).
+func (c *FakeClock) NewTimer(d time.Duration) cron.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	c.wakeUp()
+	return t
+}
+
+// Track registers the Cron using the clock, see cron.Tracker.
+func (c *FakeClock) Track(sync func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.syncs = append(c.syncs, sync)
+}
+
+// Go runs the job's goroutine, keeping track of it so that Advance can wait
+// for it to finish.
+func (c *FakeClock) Go(f func()) {
+	c.mu.Lock()
+	c.running++
+	c.mu.Unlock()
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			c.running--
+			c.changed()
+			c.mu.Unlock()
+		}()
+		f()
+	}()
+}
+
+// Sleep waits until the clock is advanced by d or ctx is done, see
+// cron.Sleeper. Advance does not wait for the jobs sleeping.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	c.mu.Lock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	t.fire = func() {
+		c.sleeping--
+		t.c <- c.now
+	}
+	c.timers = append(c.timers, t)
+	c.sleeping++
+	c.changed()
+	c.mu.Unlock()
+
+	select {
+	case <-t.c:
+		return true
+	case <-ctx.Done():
+		c.mu.Lock()
+		if t.remove() {
+			c.sleeping--
+			c.changed()
+		}
+		c.mu.Unlock()
+		return false
+	}
+}
+
+// WithDeadline returns a copy of ctx which is cancelled once the clock is
+// advanced to deadline, see cron.Sleeper.
+func (c *FakeClock) WithDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	parent, cancel := context.WithCancel(ctx)
+	dc := &deadlineContext{Context: parent, clock: c, deadline: deadline}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !deadline.After(c.now) {
+		dc.expired = true
+		cancel()
+		return dc, cancel
+	}
+	t := &fakeTimer{clock: c, deadline: deadline}
+	t.fire = func() {
+		c.waiting--
+		dc.expired = true
+		cancel()
+	}
+	c.timers = append(c.timers, t)
+	c.waiting++
+	c.changed()
+	return dc, func() {
+		c.mu.Lock()
+		if t.remove() {
+			c.waiting--
+			c.changed()
+		}
+		c.mu.Unlock()
+		cancel()
+	}
+}
+
+// changed wakes up waitJobs. The clock must be locked.
+func (c *FakeClock) changed() {
+	c.changes++
+	c.idle.Broadcast()
+}
+
+// waitJobs waits for the jobs to finish or sleep. Jobs holding a pending
+// deadline may be waiting for it, e.g. on ctx.Done(): they are considered to
+// once nothing changed for settle.
+func (c *FakeClock) waitJobs() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.running > c.sleeping {
+		if c.running > c.sleeping+c.waiting {
+			c.idle.Wait()
+			continue
+		}
+		changes := c.changes
+		c.mu.Unlock()
+		time.Sleep(settle)
+		c.mu.Lock()
+		if c.changes == changes {
+			return
+		}
+	}
+}
+
+// Advance moves the clock forward by d. Every timer due by then fires in
+// order, the clock being set to its deadline first. After each one Advance
+// waits for the Cron to start the jobs due, and for the jobs to finish.
+//
+// A job which blocks, e.g. until the Cron is stopped, therefore blocks Advance
+// too, unless it waits for the clock with Sleep, or holds a context from
+// WithDeadline which is not done yet, such as the one of cron.WithTimeout: a
+// job doing so and showing no progress for a few milliseconds of real time is
+// taken to be waiting for the deadline. The Crons using the clock must
+// not be started or stopped concurrently with Advance, and only they may create
+// timers with NewTimer.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	c.AdvanceTo(target)
+}
+
+// AdvanceTo moves the clock forward to t, like Advance. The clock does not go
+// back if t is before its current time, but the timers due are still fired.
+func (c *FakeClock) AdvanceTo(t time.Time) {
+	for {
+		c.sync()
+		c.waitJobs()
+
+		c.mu.Lock()
+		timer := c.nextTimer(t)
+		if timer == nil {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		if timer.deadline.After(c.now) {
+			c.now = timer.deadline
+		}
+		timer.remove()
+		if timer.fire != nil {
+			// timer 是 job 创建的，Cron 不会有反应
+			timer.fire()
+			c.mu.Unlock()
+			continue
+		}
+		wake := c.wake
+		timer.c <- c.now
+		c.mu.Unlock()
+
+		// The Cron creates a new timer once it has handled the fired one, or
+		// stops it if it is stopping instead.
+		<-wake
+	}
+}
+
+// wakeUp wakes up Advance waiting for the Cron to react to a timer. The clock
+// must be locked.
+func (c *FakeClock) wakeUp() {
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
+
+// sync waits for the Crons using the clock to handle the calls made so far.
+func (c *FakeClock) sync() {
+	c.mu.Lock()
+	syncs := append([]func(){}, c.syncs...)
+	c.mu.Unlock()
+	for _, sync := range syncs {
+		sync()
+	}
+}
+
+// nextTimer returns the earliest timer due by t, if any.
+func (c *FakeClock) nextTimer(t time.Time) *fakeTimer {
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+	if len(c.timers) == 0 || c.timers[0].deadline.After(t) {
+		return nil
+	}
+	return c.timers[0]
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	c        chan time.Time
+	fire     func() // set for the timers of Sleep and WithDeadline, called locked
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop removes the timer from the clock.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.wakeUp()
+	return t.remove()
+}
+
+// remove removes the timer from the clock, which must be locked. It returns
+// false if the timer was not pending.
+func (t *fakeTimer) remove() bool {
+	timers := t.clock.timers
+	for i, timer := range timers {
+		if timer == t {
+			t.clock.timers = append(timers[:i], timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// deadlineContext is a context cancelled by a FakeClock at its deadline.
+type deadlineContext struct {
+	context.Context
+	clock    *FakeClock
+	deadline time.Time
+	expired  bool // guarded by the clock's mutex
+}
+
+func (ctx *deadlineContext) Deadline() (time.Time, bool) {
+	if deadline, ok := ctx.Context.Deadline(); ok && deadline.Before(ctx.deadline) {
+		return deadline, true
+	}
+	return ctx.deadline, true
+}
+
+func (ctx *deadlineContext) Err() error {
+	err := ctx.Context.Err()
+	ctx.clock.mu.Lock()
+	defer ctx.clock.mu.Unlock()
+	if err != nil && ctx.expired {
+		return context.DeadlineExceeded
+	}
+	return err
+}
@@ -0,0 +1,293 @@
+package crontest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"main/cron"
+)
+
+// recorder records the times of the clock its job runs at.
+type recorder struct {
+	clock *FakeClock
+	mu    sync.Mutex
+	runs  []time.Time
+}
+
+func (r *recorder) Run() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, r.clock.Now())
+}
+
+func (r *recorder) check(t *testing.T, expected ...time.Time) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.runs) != len(expected) {
+		t.Fatalf("expected %d runs, got %v", len(expected), r.runs)
+	}
+	for i, run := range r.runs {
+		if !run.Equal(expected[i]) {
+			t.Errorf("run %d: expected %v, got %v", i, expected[i], run)
+		}
+	}
+	r.runs = nil
+}
+
+func newCron(t *testing.T, clock *FakeClock, loc *time.Location) *cron.Cron {
+	c := cron.New(
+		cron.WithClock(clock),
+		cron.WithLocation(loc),
+		cron.WithLogger(cron.DiscardLogger),
+		cron.WithParser(cron.NewParser(cron.Minute|cron.Hour|cron.Dom|cron.Month|cron.Dow|cron.Descriptor|cron.LastDay)),
+	)
+	c.Start()
+	t.Cleanup(func() { c.Stop() })
+	return c
+}
+
+func TestAdvance(t *testing.T) {
+	start := time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	c := newCron(t, clock, time.UTC)
+	every15 := &recorder{clock: clock}
+	hourly := &recorder{clock: clock}
+	c.AddJob("*/15 * * * *", every15)
+	c.AddJob("@hourly", hourly)
+
+	clock.Advance(14 * time.Minute)
+	every15.check(t)
+	if now := clock.Now(); !now.Equal(start.Add(14 * time.Minute)) {
+		t.Errorf("expected the clock at %v, got %v", start.Add(14*time.Minute), now)
+	}
+
+	clock.Advance(time.Hour)
+	every15.check(t,
+		start.Add(15*time.Minute), start.Add(30*time.Minute), start.Add(45*time.Minute),
+		start.Add(60*time.Minute),
+	)
+	hourly.check(t, start.Add(time.Hour))
+
+	// Entries added later are scheduled from the current time.
+	added := &recorder{clock: clock}
+	c.AddJob("@hourly", added)
+	clock.Advance(time.Hour)
+	added.check(t, start.Add(2*time.Hour))
+}
+
+func TestAdvanceDaylightSaving(t *testing.T) {
+	nyc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	clock := NewFakeClock(time.Date(2012, 3, 10, 0, 0, 0, 0, nyc))
+	c := newCron(t, clock, nyc)
+	r := &recorder{clock: clock}
+	c.AddJob("30 2 * * *", r)
+
+	// 2:30 does not exist on March 11.
+	clock.AdvanceTo(time.Date(2012, 3, 13, 0, 0, 0, 0, nyc))
+	r.check(t, time.Date(2012, 3, 10, 2, 30, 0, 0, nyc), time.Date(2012, 3, 12, 2, 30, 0, 0, nyc))
+}
+
+func TestAdvanceMonthBoundaries(t *testing.T) {
+	clock := NewFakeClock(time.Date(2022, 1, 15, 0, 0, 0, 0, time.UTC))
+	c := newCron(t, clock, time.UTC)
+	r := &recorder{clock: clock}
+	c.AddJob("0 12 L * *", r)
+
+	clock.AdvanceTo(time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC))
+	r.check(t,
+		time.Date(2022, 1, 31, 12, 0, 0, 0, time.UTC),
+		time.Date(2022, 2, 28, 12, 0, 0, 0, time.UTC),
+		time.Date(2022, 3, 31, 12, 0, 0, 0, time.UTC),
+	)
+}
+
+func TestAdvanceStopped(t *testing.T) {
+	start := time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	c := newCron(t, clock, time.UTC)
+	r := &recorder{clock: clock}
+	c.AddJob("@hourly", r)
+	c.Stop()
+
+	clock.Advance(2 * time.Hour)
+	r.check(t)
+	if now := clock.Now(); !now.Equal(start.Add(2 * time.Hour)) {
+		t.Errorf("expected the clock at %v, got %v", start.Add(2*time.Hour), now)
+	}
+}
+
+// failing fails the given number of times, recording the time of each run and
+// the deadline of its context.
+type failing struct {
+	clock     *FakeClock
+	failures  int
+	mu        sync.Mutex
+	runs      []time.Time
+	deadlines []time.Time
+}
+
+func (f *failing) Run(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runs = append(f.runs, f.clock.Now())
+	deadline, _ := ctx.Deadline()
+	f.deadlines = append(f.deadlines, deadline)
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("YOLO")
+	}
+	return nil
+}
+
+func (f *failing) check(t *testing.T, runs, deadlines []time.Time) {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !reflect.DeepEqual(f.runs, runs) {
+		t.Errorf("expected runs %v, got %v", runs, f.runs)
+	}
+	if !reflect.DeepEqual(f.deadlines, deadlines) {
+		t.Errorf("expected deadlines %v, got %v", deadlines, f.deadlines)
+	}
+	f.runs, f.deadlines = nil, nil
+}
+
+func TestAdvanceRetry(t *testing.T) {
+	start := time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	c := newCron(t, clock, time.UTC)
+	retry := cron.Retry(cron.DiscardLogger, cron.RetryPolicy{MaxAttempts: 5, Backoff: 20 * time.Minute, MaxBackoff: 20 * time.Minute})
+	job := &failing{clock: clock, failures: 4}
+	c.AddJob("@hourly", retry(cron.ContextJob(job)))
+
+	// 13:00 fails and waits for its retry at 13:20.
+	clock.Advance(time.Hour)
+	job.check(t, []time.Time{start.Add(time.Hour)}, []time.Time{{}})
+
+	// 13:20 and 13:40 fail, and a retry at 14:00 would overlap the next run:
+	// the job gives up. The run at 14:00 fails again.
+	clock.Advance(time.Hour)
+	next := start.Add(2 * time.Hour)
+	job.check(t,
+		[]time.Time{start.Add(80 * time.Minute), start.Add(100 * time.Minute), next},
+		[]time.Time{next, next, {}},
+	)
+
+	// The retry at 14:20 succeeds.
+	clock.Advance(20 * time.Minute)
+	job.check(t, []time.Time{next.Add(20 * time.Minute)}, []time.Time{next.Add(time.Hour)})
+}
+
+func TestWithDeadline(t *testing.T) {
+	start := time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	ctx, cancel := clock.WithDeadline(context.Background(), start.Add(time.Minute))
+	defer cancel()
+
+	clock.Advance(59 * time.Second)
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("expected the context to be alive, got %v", err)
+	}
+	clock.Advance(time.Second)
+	<-ctx.Done()
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+
+	ctx, cancel = clock.WithDeadline(context.Background(), start.Add(time.Hour))
+	cancel()
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("expected the context to be cancelled, got %v", err)
+	}
+	if clock.Sleep(ctx, time.Hour) {
+		t.Error("expected Sleep to stop with the context")
+	}
+}
+
+// WithTimeout cancels the job at the deadline of the clock, and Advance does
+// not wait for the job waiting for it.
+func TestAdvanceTimeout(t *testing.T) {
+	start := time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	c := newCron(t, clock, time.UTC)
+	var (
+		mu        sync.Mutex
+		cancelled time.Time
+		err       error
+	)
+	c.AddJob("@hourly", cron.WithTimeout(10*time.Minute)(cron.ContextJob(cron.FuncJobWithContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		cancelled, err = clock.Now(), ctx.Err()
+		return err
+	}))))
+
+	clock.Advance(time.Hour)
+	mu.Lock()
+	if !cancelled.IsZero() {
+		t.Errorf("expected the job to wait for its timeout, got cancelled at %v", cancelled)
+	}
+	mu.Unlock()
+
+	clock.Advance(20 * time.Minute)
+	mu.Lock()
+	defer mu.Unlock()
+	if expected := start.Add(70 * time.Minute); !cancelled.Equal(expected) {
+		t.Errorf("expected the job to be cancelled at %v, got %v", expected, cancelled)
+	}
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+// Leases expire on the clock of the Crons taking them.
+func TestAdvanceLocker(t *testing.T) {
+	dir := t.TempDir()
+	lockers := map[string]cron.Locker{
+		"memory": cron.NewMemoryLocker(time.Hour),
+		"file":   cron.NewFileLocker(dir, time.Hour),
+	}
+	for name, locker := range lockers {
+		start := time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC)
+		clock := NewFakeClock(start)
+		r := &recorder{clock: clock}
+		for i := 0; i < 2; i++ {
+			c := cron.New(cron.WithClock(clock), cron.WithLocation(time.UTC), cron.WithLogger(cron.DiscardLogger),
+				cron.WithLocker(locker))
+			c.AddJob("@hourly", r, cron.WithName("job"))
+			c.Start()
+			defer c.Stop()
+		}
+
+		clock.Advance(3 * time.Hour)
+		r.mu.Lock()
+		if len(r.runs) != 3 {
+			t.Errorf("%s: expected each activation to run once, got %v", name, r.runs)
+		}
+		r.mu.Unlock()
+	}
+
+	files, _ := os.ReadDir(dir)
+	var leases []string
+	for _, file := range files {
+		leases = append(leases, file.Name())
+	}
+	expected := []string{
+		fmt.Sprintf("job,%d.lock", time.Date(2022, 12, 10, 14, 0, 0, 0, time.UTC).Unix()),
+		fmt.Sprintf("job,%d.lock", time.Date(2022, 12, 10, 15, 0, 0, 0, time.UTC).Unix()),
+	}
+	if !reflect.DeepEqual(leases, expected) {
+		t.Errorf("expected the expired leases to be removed, got %v", leases)
+	}
+}
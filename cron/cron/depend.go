@@ -0,0 +1,238 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// afterSchedule is the Schedule of an entry which runs after the entries it
+// depends on rather than on its own schedule, so it never activates by itself.
+type afterSchedule struct {
+	Schedule // the entry's own schedule, used again once it has no dependencies
+	deps     []EntryID
+}
+
+func (afterSchedule) Next(time.Time) time.Time { return time.Time{} }
+
+// neverSchedule is the own schedule of the entries added by AddJobAfter.
+type neverSchedule struct{}
+
+func (neverSchedule) Next(time.Time) time.Time { return time.Time{} }
+
+// dependRequest asks the running scheduler to add dependencies to an entry.
+type dependRequest struct {
+	id   EntryID
+	deps []EntryID
+	err  chan error
+}
+
+// runResult tells the running scheduler whether a run of an entry with
+// dependents succeeded.
+type runResult struct {
+	id EntryID
+	ok bool
+}
+
+// DependsOn returns the IDs of the entries this entry runs after, see
+// Cron.AddDependency. It is empty for entries run on their schedule.
+func (e Entry) DependsOn() []EntryID {
+	if s, ok := e.Schedule.(afterSchedule); ok {
+		return append([]EntryID(nil), s.deps...)
+	}
+	return nil
+}
+
+// AddFuncAfter adds a func to the Cron to be run after the given entries, see
+// AddJobAfter.
+func (c *Cron) AddFuncAfter(deps []EntryID, cmd func(), opts ...EntryOption) (EntryID, error) {
+	return c.AddJobAfter(deps, FuncJob(cmd), opts...)
+}
+
+// AddJobAfter adds a Job to the Cron which has no schedule of its own: it is
+// run each time all of the given entries succeeded since its last run. See
+// AddDependency.
+func (c *Cron) AddJobAfter(deps []EntryID, cmd Job, opts ...EntryOption) (EntryID, error) {
+	id := c.Schedule(neverSchedule{}, cmd, opts...)
+	if err := c.AddDependency(id, deps...); err != nil {
+		c.Remove(id)
+		return 0, err
+	}
+	return id, nil
+}
+
+// AddDependency makes the entry run after the given entries instead of on its
+// schedule. From then on it runs as soon as every entry it depends on finished
+// a run successfully since its own last run: an entry depending on A and B
+// runs once per cycle in which both A and B succeeded, whatever their order.
+// Runs which return an error or panic do not count, and cancel the earlier
+// success of their entry: a dependent runs after the last run of each entry
+// it depends on succeeded.
+//
+// It returns an error, leaving the entry unchanged, if one of the entries does
+// not exist or the dependency would create a cycle. Removing an entry drops it
+// from the dependencies of others; an entry left without dependencies runs on
+// its own schedule again.
+//
+// Dependent entries are only run while the Cron is running.
+func (c *Cron) AddDependency(id EntryID, deps ...EntryID) error {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+	if c.running {
+		req := dependRequest{id, deps, make(chan error, 1)}
+		c.depend <- req
+		return <-req.err
+	}
+	return c.addDependencies(id, deps)
+}
+
+// addDependencies validates and adds the dependencies of the entry.
+func (c *Cron) addDependencies(id EntryID, deps []EntryID) error {
+	e := c.entry(id)
+	if e == nil {
+		return fmt.Errorf("unknown entry %d", id)
+	}
+	after, ok := e.Schedule.(afterSchedule)
+	if !ok {
+		after = afterSchedule{Schedule: e.Schedule}
+	}
+	for _, dep := range deps {
+		if c.entry(dep) == nil {
+			return fmt.Errorf("unknown entry %d", dep)
+		}
+		if c.dependsOn(dep, id) {
+			return fmt.Errorf("entry %d depending on %d would create a cycle", id, dep)
+		}
+	}
+	// The slice is shared with snapshots, so it is copied rather than appended to.
+	all := append([]EntryID(nil), after.deps...)
+	for _, dep := range deps {
+		if !containsID(all, dep) {
+			all = append(all, dep)
+		}
+	}
+	after.deps = all
+	e.Schedule = after
+	e.Next = time.Time{}
+	c.logger.Info("depends", "entry", id, "on", all)
+	return nil
+}
+
+// dependsOn reports whether the entry id is target, or depends on it directly
+// or through other entries.
+func (c *Cron) dependsOn(id, target EntryID) bool {
+	visited := make(map[EntryID]bool)
+	var visit func(id EntryID) bool
+	visit = func(id EntryID) bool {
+		if id == target {
+			return true
+		}
+		if visited[id] {
+			return false
+		}
+		visited[id] = true
+		if e := c.entry(id); e != nil {
+			for _, dep := range e.DependsOn() {
+				if visit(dep) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return visit(id)
+}
+
+// dependents returns the entries depending directly on the entry id.
+func (c *Cron) dependents(id EntryID) []*Entry {
+	var entries []*Entry
+	for _, e := range c.entries {
+		if s, ok := e.Schedule.(afterSchedule); ok && containsID(s.deps, id) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// dropDependency removes the entry id, which is being removed, from the
+// dependencies of the other entries.
+func (c *Cron) dropDependency(id EntryID, now time.Time) {
+	for _, e := range c.dependents(id) {
+		s := e.Schedule.(afterSchedule)
+		var deps []EntryID
+		for _, dep := range s.deps {
+			if dep != id {
+				deps = append(deps, dep)
+			}
+		}
+		delete(e.succeeded, id)
+		if len(deps) > 0 {
+			e.Schedule = afterSchedule{s.Schedule, deps}
+			continue
+		}
+		e.Schedule = s.Schedule
+		e.succeeded = nil
+		if !e.Paused {
+			e.Next = e.Schedule.Next(now)
+		}
+	}
+}
+
+// jobFinished tells the running scheduler how a run of the entry ended, so
+// that its dependents may run. It is called on the job's goroutine.
+func (c *Cron) jobFinished(res runResult) {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+	if c.running {
+		c.results <- res
+	}
+}
+
+// runDependents records how a run of the entry ended and runs the dependents
+// for which it was the last dependency to succeed. A failed run takes back
+// the entry's earlier success: dependents wait for it to succeed again.
+func (c *Cron) runDependents(res runResult, now time.Time) {
+	id := res.id
+	for _, e := range c.dependents(id) {
+		if !res.ok {
+			delete(e.succeeded, id)
+			continue
+		}
+		if e.Paused {
+			continue
+		}
+		if e.succeeded == nil {
+			e.succeeded = make(map[EntryID]bool)
+		}
+		e.succeeded[id] = true
+		deps := e.DependsOn()
+		if len(e.succeeded) < len(deps) {
+			continue
+		}
+		e.succeeded = nil
+		e.Prev = now
+		c.startJob(e, now)
+		c.logger.Info("run after", "entry", e.ID, "after", id)
+	}
+}
+
+func containsID(ids []EntryID, id EntryID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// describeAfter describes the schedule of a dependent entry.
+func describeAfter(s afterSchedule) string {
+	ids := make([]string, len(s.deps))
+	for i, id := range s.deps {
+		ids[i] = strconv.Itoa(int(id))
+	}
+	if len(ids) == 1 {
+		return "After entry " + ids[0] + " succeeds"
+	}
+	return "After entries " + joinList(ids) + " succeed"
+}
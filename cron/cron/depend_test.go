@@ -0,0 +1,203 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAddJobAfter(t *testing.T) {
+	cron := New(WithParser(secondParser), WithChain(), WithLogger(DiscardLogger))
+	ran := make(chan EntryID, 10)
+	var failedRuns int64
+	a, _ := cron.AddFunc("* * * * * ?", func() {})
+	failing, _ := cron.AddFuncWithContext("* * * * * ?", func(context.Context) error {
+		atomic.AddInt64(&failedRuns, 1)
+		return errors.New("YOLO")
+	})
+	b, err := cron.AddFuncAfter([]EntryID{a}, func() { ran <- a })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cron.AddFuncAfter([]EntryID{failing}, func() { ran <- failing }); err != nil {
+		t.Fatal(err)
+	}
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected the dependent job to run")
+	case id := <-ran:
+		if id != a {
+			t.Errorf("expected the job depending on entry %d to run, got the one depending on %d", a, id)
+		}
+	}
+	if atomic.LoadInt64(&failedRuns) == 0 {
+		t.Error("expected the failing job to run")
+	}
+
+	entry := cron.Entry(b)
+	if deps := entry.DependsOn(); !reflect.DeepEqual(deps, []EntryID{a}) {
+		t.Errorf("expected entry %d to depend on %d, got %v", b, a, deps)
+	}
+	if !entry.Next.IsZero() || entry.Prev.IsZero() {
+		t.Errorf("expected only a previous run, got prev %v and next %v", entry.Prev, entry.Next)
+	}
+	if desc := Describe(entry.Schedule); desc != "After entry 1 succeeds" {
+		t.Errorf("unexpected description %q", desc)
+	}
+}
+
+// An entry with several dependencies runs once all of them succeeded.
+func TestDependencyFanIn(t *testing.T) {
+	cron := New(WithChain(), WithLogger(DiscardLogger))
+	cron.jobCtx = context.Background()
+	var calls int64
+	a := cron.Schedule(neverSchedule{}, FuncJob(func() {}))
+	b := cron.Schedule(neverSchedule{}, FuncJob(func() {}))
+	c, err := cron.AddFuncAfter([]EntryID{a, b}, func() { atomic.AddInt64(&calls, 1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	cron.runDependents(runResult{a, true}, now)
+	cron.runDependents(runResult{a, true}, now)
+	cron.jobWaiter.Wait()
+	if actual := atomic.LoadInt64(&calls); actual != 0 {
+		t.Fatalf("expected no run before %d succeeded, got %d", b, actual)
+	}
+	cron.runDependents(runResult{b, true}, now)
+	cron.jobWaiter.Wait()
+	if actual := atomic.LoadInt64(&calls); actual != 1 {
+		t.Fatalf("expected 1 run, got %d", actual)
+	}
+	if prev := cron.Entry(c).Prev; !prev.Equal(now) {
+		t.Errorf("expected the run at %v, got %v", now, prev)
+	}
+
+	// The next cycle starts over.
+	cron.runDependents(runResult{b, true}, now)
+	cron.jobWaiter.Wait()
+	if actual := atomic.LoadInt64(&calls); actual != 1 {
+		t.Errorf("expected the second cycle to wait for %d, got %d runs", a, actual)
+	}
+	if desc := Describe(cron.Entry(c).Schedule); desc != "After entries 1 and 2 succeed" {
+		t.Errorf("unexpected description %q", desc)
+	}
+}
+
+// A failed run takes back the earlier success of its entry.
+func TestDependencyFailure(t *testing.T) {
+	cron := New(WithChain(), WithLogger(DiscardLogger))
+	cron.jobCtx = context.Background()
+	var calls int64
+	fail := false
+	a := cron.Schedule(neverSchedule{}, ContextJob(FuncJobWithContext(func(context.Context) error {
+		if fail {
+			return errors.New("YOLO")
+		}
+		return nil
+	})))
+	b := cron.Schedule(neverSchedule{}, FuncJob(func() {}))
+	if _, err := cron.AddFuncAfter([]EntryID{a, b}, func() { atomic.AddInt64(&calls, 1) }); err != nil {
+		t.Fatal(err)
+	}
+
+	// The job reports how each run of a ended to the scheduler, which is
+	// pretended to be running.
+	cron.running = true
+	run := func(day time.Time) runResult {
+		cron.startJob(cron.entry(a), day)
+		return <-cron.results
+	}
+
+	monday := time.Date(2022, 12, 5, 2, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	res := run(monday)
+	if !res.ok {
+		t.Fatal("expected the run on Monday to succeed")
+	}
+	cron.runDependents(res, monday)
+	fail = true
+	res = run(tuesday)
+	if res.ok {
+		t.Fatal("expected the run on Tuesday to fail")
+	}
+	cron.runDependents(res, tuesday)
+	cron.runDependents(runResult{b, true}, tuesday)
+	cron.jobWaiter.Wait()
+	if actual := atomic.LoadInt64(&calls); actual != 0 {
+		t.Errorf("expected no run after %d failed, got %d", a, actual)
+	}
+}
+
+func TestDependencyErrors(t *testing.T) {
+	cron := New(WithParser(secondParser))
+	a, _ := cron.AddFunc("@every 1s", func() {})
+	b, _ := cron.AddFuncAfter([]EntryID{a}, func() {})
+	c, _ := cron.AddFuncAfter([]EntryID{b}, func() {})
+
+	tests := []struct {
+		id   EntryID
+		deps []EntryID
+	}{
+		{a, []EntryID{a}},
+		{a, []EntryID{c}},
+		{b, []EntryID{c}},
+		{a, []EntryID{42}},
+		{42, []EntryID{a}},
+	}
+	for _, test := range tests {
+		if err := cron.AddDependency(test.id, test.deps...); err == nil {
+			t.Errorf("%d after %v: expected an error", test.id, test.deps)
+		}
+	}
+	if deps := cron.Entry(a).DependsOn(); deps != nil {
+		t.Errorf("expected entry %d to keep its schedule, got dependencies %v", a, deps)
+	}
+
+	if _, err := cron.AddFuncAfter([]EntryID{42}, func() {}); err == nil {
+		t.Error("expected an error for an unknown entry")
+	}
+	if n := len(cron.Entries()); n != 3 {
+		t.Errorf("expected the failed entry to be removed, got %d entries", n)
+	}
+
+	// The graph may still fan out and in without cycles.
+	if err := cron.AddDependency(c, a); err != nil {
+		t.Errorf("expected entry %d to depend on %d, got %v", c, a, err)
+	}
+}
+
+func TestRemoveDependency(t *testing.T) {
+	cron := New(WithParser(secondParser), WithChain(), WithLogger(DiscardLogger))
+	a, _ := cron.AddFunc("@every 1s", func() {})
+	b, _ := cron.AddFunc("@every 1s", func() {})
+	c, _ := cron.AddFunc("@every 1s", func() {})
+	d, _ := cron.AddFuncAfter([]EntryID{a}, func() {})
+	if err := cron.AddDependency(c, a, b); err != nil {
+		t.Fatal(err)
+	}
+	cron.Start()
+	defer cron.Stop()
+
+	if next := cron.Entry(c).Next; !next.IsZero() {
+		t.Fatalf("expected entry %d not to be scheduled, got %v", c, next)
+	}
+	cron.Remove(a)
+	if deps := cron.Entry(c).DependsOn(); !reflect.DeepEqual(deps, []EntryID{b}) {
+		t.Errorf("expected entry %d to depend on %d only, got %v", c, b, deps)
+	}
+	cron.Remove(b)
+	if entry := cron.Entry(c); entry.DependsOn() != nil || entry.Next.IsZero() {
+		t.Errorf("expected entry %d back on its schedule, got %v", c, entry.Next)
+	}
+	if entry := cron.Entry(d); entry.DependsOn() != nil || !entry.Next.IsZero() {
+		t.Errorf("expected entry %d never to run, got %v", d, entry.Next)
+	}
+}
@@ -0,0 +1,327 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Describe returns an English description of the schedule, such as
+// "At 02:30 on Monday through Friday" for the spec "30 2 * * 1-5".
+//
+// It understands the schedules returned by the parser (including the
+// descriptors) and those of this package. Other schedules are described by
+// their type.
+func Describe(schedule Schedule) string {
+	switch s := schedule.(type) {
+	case *SpecSchedule:
+		return describeSpec(s, dayRules{})
+	case dayRuleSchedule:
+		return describeSpec(s.SpecSchedule, s.rules)
+	case ConstantDelaySchedule:
+		return "Every " + formatDuration(s.Delay)
+	case jitterSchedule:
+		return Describe(s.Schedule) + ", delayed by " + formatDuration(s.offset)
+	case excludingSchedule:
+		return Describe(s.Schedule) + ", except on excluded dates and periods"
+	case afterSchedule:
+		return describeAfter(s)
+	case neverSchedule:
+		return "Never"
+	case atSchedule, timesSchedule, betweenSchedule:
+		return describeBounds(s)
+	}
+	return fmt.Sprintf("Custom schedule (%T)", schedule)
+}
+
+// NextN returns the next n activation times of the schedule after from, in
+// the order a Cron would run them. It stops early if the schedule runs out of
+// activation times.
+//
+// The times come from the schedule's Next, so they are exactly those a Cron
+// would use, including around daylight saving time transitions: wall clock
+// times skipped when the clocks go forward are not run, and a SpecSchedule
+// matching a wall clock time which happens twice when the clocks go back runs
+// at both instants.
+func NextN(schedule Schedule, from time.Time, n int) []time.Time {
+	var times []time.Time
+	for t := from; len(times) < n; {
+		next := schedule.Next(t)
+		if next.IsZero() || !next.After(t) {
+			break
+		}
+		times = append(times, next)
+		t = next
+	}
+	return times
+}
+
+var (
+	dowNames = []string{
+		"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday",
+	}
+	monthNames = []string{
+		"", "January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December",
+	}
+)
+
+// describeSpec describes the times of day, then the days, months and time
+// zone of the schedule.
+func describeSpec(s *SpecSchedule, rules dayRules) string {
+	desc := describeTimeOfDay(s) + describeDays(s, rules)
+	if !isAll(s.Month, months) {
+		desc += " in " + describeValues(fieldValues(s.Month, months), monthNames)
+	}
+	if s.Location != nil && s.Location != time.Local {
+		desc += " (" + s.Location.String() + ")"
+	}
+	return desc
+}
+
+// describeTimeOfDay describes the second, minute and hour fields, either as a
+// list of clock times or as "every ..." phrases.
+func describeTimeOfDay(s *SpecSchedule) string {
+	var (
+		secs = fieldValues(s.Second, seconds)
+		mins = fieldValues(s.Minute, minutes)
+		hrs  = fieldValues(s.Hour, hours)
+	)
+	if len(secs)*len(mins)*len(hrs) <= 4 {
+		var clocks []string
+		for _, h := range hrs {
+			for _, m := range mins {
+				for _, sec := range secs {
+					clock := fmt.Sprintf("%02d:%02d", h, m)
+					if sec != 0 {
+						clock += fmt.Sprintf(":%02d", sec)
+					}
+					clocks = append(clocks, clock)
+				}
+			}
+		}
+		return "At " + joinList(clocks)
+	}
+
+	fields := []struct {
+		values []uint
+		r      bounds
+		unit   string
+	}{
+		{secs, seconds, "second"},
+		{mins, minutes, "minute"},
+		{hrs, hours, "hour"},
+	}
+	first := 0
+	if len(secs) == 1 && secs[0] == 0 {
+		first = 1 // At second 0 is implied.
+	}
+
+	var parts []string
+	periodic := false // whether the previous field repeats over the whole next one
+	for i := first; i < len(fields); i++ {
+		f := fields[i]
+		step := fieldStep(f.values, f.r)
+		switch {
+		case step == 1:
+			if !periodic {
+				parts = append(parts, "every "+f.unit)
+			}
+			periodic = true
+		case step > 1:
+			if i == first {
+				parts = append(parts, fmt.Sprintf("every %d %ss", step, f.unit))
+			} else {
+				parts = append(parts, fmt.Sprintf("every %s %s", ordinal(step), f.unit))
+			}
+			periodic = true
+		default:
+			phrase := f.unit + " " + describeValues(f.values, nil)
+			if i == first {
+				phrase = "at " + phrase
+			}
+			parts = append(parts, phrase)
+			periodic = false
+		}
+	}
+	desc := strings.Join(parts, " past ")
+	return strings.ToUpper(desc[:1]) + desc[1:]
+}
+
+// describeDays describes the day of month and day of week fields. Like in
+// dayMatches, they are alternatives when both are restricted.
+func describeDays(s *SpecSchedule, rules dayRules) string {
+	var phrases []string
+	if s.Dom&starBit == 0 {
+		if d := describeDom(s, rules); d != "" {
+			phrases = append(phrases, "on "+d)
+		}
+	}
+	if s.Dow&starBit == 0 {
+		if d := describeDow(s, rules); d != "" {
+			phrases = append(phrases, "on "+d)
+		}
+	}
+	if len(phrases) == 0 {
+		return ""
+	}
+	return " " + strings.Join(phrases, " or ")
+}
+
+func describeDom(s *SpecSchedule, rules dayRules) string {
+	var items []string
+	if values := fieldValues(s.Dom, dom); len(values) > 0 && !isAll(s.Dom, dom) {
+		if step := fieldStep(values, dom); step > 1 {
+			items = append(items, "every "+ordinal(step)+" day")
+		} else {
+			items = append(items, "day "+describeValues(values, nil))
+		}
+	}
+	for offset := uint(0); offset < dom.max; offset++ {
+		if 1<<offset&rules.lastDom == 0 {
+			continue
+		}
+		if offset == 0 {
+			items = append(items, "the last day")
+		} else {
+			items = append(items, fmt.Sprintf("the %s to last day", ordinal(offset+1)))
+		}
+	}
+	if rules.lastWeekday {
+		items = append(items, "the last weekday")
+	}
+	for day := dom.min; day <= dom.max; day++ {
+		if 1<<day&rules.weekdayDom > 0 {
+			items = append(items, fmt.Sprintf("the weekday nearest day %d", day))
+		}
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return joinList(items) + " of the month"
+}
+
+func describeDow(s *SpecSchedule, rules dayRules) string {
+	var items []string
+	if values := fieldValues(s.Dow, dow); len(values) > 0 && !isAll(s.Dow, dow) {
+		items = append(items, describeValues(values, dowNames))
+	}
+	var monthly []string
+	for day := dow.min; day <= dow.max; day++ {
+		if 1<<day&rules.lastDow > 0 {
+			monthly = append(monthly, "the last "+dowNames[day])
+		}
+		for nth := uint(1); nth <= 5; nth++ {
+			if 1<<(day*8+nth)&rules.nthDow > 0 {
+				monthly = append(monthly, fmt.Sprintf("the %s %s", ordinal(nth), dowNames[day]))
+			}
+		}
+	}
+	if len(monthly) > 0 {
+		items = append(items, joinList(monthly)+" of the month")
+	}
+	return joinList(items)
+}
+
+// fieldValues returns the values set in the bits, within the bounds.
+func fieldValues(bits uint64, r bounds) []uint {
+	var values []uint
+	for v := r.min; v <= r.max; v++ {
+		if 1<<v&bits > 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// isAll returns true if every value within the bounds is set.
+func isAll(bits uint64, r bounds) bool {
+	all := getBits(r.min, r.max, 1)
+	return bits&all == all
+}
+
+// fieldStep returns the step if the values are a range over the whole field
+// ("*" or "*/step"), or 0 if they are not.
+func fieldStep(values []uint, r bounds) uint {
+	if len(values) < 2 || values[0] != r.min {
+		return 0
+	}
+	step := values[1] - values[0]
+	for i := 2; i < len(values); i++ {
+		if values[i]-values[i-1] != step {
+			return 0
+		}
+	}
+	if values[len(values)-1]+step <= r.max {
+		return 0
+	}
+	return step
+}
+
+// describeValues lists the values, collapsing runs of three or more into
+// "a through b". Names, if given, are used instead of the numbers.
+func describeValues(values []uint, names []string) string {
+	name := func(v uint) string {
+		if names != nil {
+			return names[v]
+		}
+		return fmt.Sprint(v)
+	}
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			items = append(items, name(values[i])+" through "+name(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				items = append(items, name(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return joinList(items)
+}
+
+// joinList joins the items as in "a, b and c".
+func joinList(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// ordinal returns "1st", "2nd", "3rd", "4th", ... for n.
+func ordinal(n uint) string {
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// formatDuration formats the duration without trailing zero units, e.g. "1h"
+// instead of "1h0m0s".
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
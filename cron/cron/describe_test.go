@@ -0,0 +1,101 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	parser := NewParser(SecondOptional | Minute | Hour | Dom | Month | Dow | Descriptor |
+		LastDay | NearestWeekday | NthDayOfWeek)
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"30 2 * * 1-5", "At 02:30 on Monday through Friday"},
+		{"* * * * *", "Every minute"},
+		{"0,30 * * * *", "Every 30 minutes"},
+		{"*/10 * * * * *", "Every 10 seconds"},
+		{"15 * * * * *", "At second 15 past every minute"},
+		{"0 * * * *", "At minute 0 past every hour"},
+		{"0 */2 * * *", "At minute 0 past every 2nd hour"},
+		{"*/15 9-17 * * *", "Every 15 minutes past hour 9 through 17"},
+		{"5/15 * * * *", "At minute 5, 20, 35 and 50 past every hour"},
+		{"0 9,17 * * *", "At 09:00 and 17:00"},
+		{"30 0 9 * * *", "At 09:00:30"},
+		{"@weekly", "At 00:00 on Sunday"},
+		{"@yearly", "At 00:00 on day 1 of the month in January"},
+		{"@every 1h30m", "Every 1h30m"},
+		{"@every 2h", "Every 2h"},
+		{"0 0 */2 * *", "At 00:00 on every 2nd day of the month"},
+		{"0 0 1,15 * MON", "At 00:00 on day 1 and 15 of the month or on Monday"},
+		{"0 0 1 jan-mar *", "At 00:00 on day 1 of the month in January through March"},
+		{"0 0 L * ?", "At 00:00 on the last day of the month"},
+		{"0 0 L-2 * ?", "At 00:00 on the 3rd to last day of the month"},
+		{"0 18 LW * ?", "At 18:00 on the last weekday of the month"},
+		{"0 0 15W * ?", "At 00:00 on the weekday nearest day 15 of the month"},
+		{"0 0 ? * 5L", "At 00:00 on the last Friday of the month"},
+		{"0 0 ? * 5#3", "At 00:00 on the 3rd Friday of the month"},
+		{"CRON_TZ=Asia/Tokyo 30 4 * * *", "At 04:30 (Asia/Tokyo)"},
+	}
+	for _, test := range tests {
+		sched, err := parser.Parse(test.spec)
+		if err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+			continue
+		}
+		if actual := Describe(sched); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.spec, test.expected, actual)
+		}
+	}
+
+	if actual := Describe(funcSchedule(nil)); actual != "Custom schedule (cron.funcSchedule)" {
+		t.Errorf("unexpected description of a custom schedule: %q", actual)
+	}
+}
+
+func TestNextN(t *testing.T) {
+	tests := []struct {
+		spec     string
+		from     string
+		expected []string
+	}{
+		{"0 9 * * 1-5", "Fri Dec 9 12:00 2022", []string{
+			"Mon Dec 12 09:00 2022", "Tue Dec 13 09:00 2022", "Wed Dec 14 09:00 2022",
+		}},
+
+		// Spring forward: 2:30 does not exist on Mar 11.
+		{"TZ=America/New_York 30 2 * * *", "2012-03-10T00:00:00-0500", []string{
+			"2012-03-10T02:30:00-0500", "2012-03-12T02:30:00-0400", "2012-03-13T02:30:00-0400",
+		}},
+
+		// Fall back: 1:30 happens twice on Nov 4.
+		{"TZ=America/New_York 30 1 * * *", "2012-11-04T00:00:00-0400", []string{
+			"2012-11-04T01:30:00-0400", "2012-11-04T01:30:00-0500", "2012-11-05T01:30:00-0500",
+		}},
+
+		// Never fires.
+		{"0 0 30 2 *", "Fri Dec 9 12:00 2022", nil},
+	}
+	for _, test := range tests {
+		sched, err := ParseStandard(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := NextN(sched, getTime(test.from), 3)
+		if len(actual) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.spec, test.expected, actual)
+			continue
+		}
+		for i, next := range actual {
+			if expected := getTime(test.expected[i]); !next.Equal(expected) {
+				t.Errorf("%s: expected %v, got %v", test.spec, expected, next)
+			}
+		}
+	}
+}
+
+// funcSchedule adapts a function to the Schedule interface.
+type funcSchedule func(time.Time) time.Time
+
+func (f funcSchedule) Next(t time.Time) time.Time { return f(t) }
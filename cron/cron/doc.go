@@ -34,7 +34,13 @@ them in their own goroutines.
 	// Inspect the cron job entries' next and previous run times.
 	inspect(c.Entries())
 	..
-	c.Stop()  // Stop the scheduler (does not stop any jobs already running).
+	// Pause an entry, run it on demand, and resume its schedule.
+	id, _ := c.AddFunc("@hourly", func() { fmt.Println("Every hour") })
+	c.Pause(id)
+	c.RunNow(id)
+	c.Resume(id)
+	..
+	c.Stop()  // Stop the scheduler (does not wait for jobs already running).
 
 CRON Expression Format
 
@@ -44,9 +50,9 @@ A cron expression represents a set of times, using 5 space-separated fields.
 	----------   | ---------- | --------------  | --------------------------
 	Minutes      | Yes        | 0-59            | * / , -
 	Hours        | Yes        | 0-23            | * / , -
-	Day of month | Yes        | 1-31            | * / , - ?
+	Day of month | Yes        | 1-31            | * / , - ? L W
 	Month        | Yes        | 1-12 or JAN-DEC | * / , -
-	Day of week  | Yes        | 0-6 or SUN-SAT  | * / , - ?
+	Day of week  | Yes        | 0-6 or SUN-SAT  | * / , - ? L #
 
 Month and Day-of-week field values are case insensitive.  "SUN", "Sun", and
 "sun" are equally accepted.
@@ -105,6 +111,33 @@ Question mark ( ? )
 Question mark may be used instead of '*' for leaving either day-of-month or
 day-of-week blank.
 
+Last ( L ), weekday ( W ) and hash ( # )
+
+These Quartz extensions are only accepted by parsers created with the LastDay,
+NearestWeekday and NthDayOfWeek options respectively:
+
+	cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow |
+		cron.LastDay | cron.NearestWeekday | cron.NthDayOfWeek)
+
+In the day-of-month field, "L" is the last day of the month and "L-3" the third
+day before it. "15W" is the weekday (Monday to Friday) nearest to the 15th,
+without leaving the month, and "LW" the last weekday of the month. In the
+day-of-week field, "5L" is the last Friday of the month and "5#3" the third
+Friday of the month. For example, "0 18 LW * ?" runs at 6pm on the last
+business day of every month.
+
+Hash ( H )
+
+Parsers created with the Hash option accept Jenkins style "H" tokens, which
+stand for a value derived from the name (or ID) of the entry. "H * * * *" runs
+hourly at a minute which differs from job to job but stays the same between
+runs, "H(0-29)" picks a value within 0-29 and "H/15" runs every 15 minutes
+starting from a hashed minute below 15. This spreads the load of many jobs
+sharing a spec. The WithJitter entry option does the same for any schedule, by
+delaying each activation of the entry by a fixed offset:
+
+	c.AddFunc("@hourly", backup, cron.WithName("backup"), cron.WithJitter(10*time.Minute))
+
 Predefined schedules
 
 You may use one of several pre-defined schedules in place of a cron expression.
@@ -134,6 +167,33 @@ Note: The interval does not take the job runtime into account.  For example,
 if a job takes 3 minutes to run, and it is scheduled to run every 5 minutes,
 it will have only 2 minutes of idle time between each run.
 
+One-shot and bounded schedules
+
+At returns a Schedule which runs once, Times limits the number of runs of a
+schedule and Between restricts it to a window of time:
+
+	c.Schedule(cron.At(launch), announce)
+	c.Schedule(cron.Times(cron.Every(time.Minute), 3), probe)
+	c.Schedule(cron.Between(sched, campaignStart, campaignEnd), remind)
+
+Once such a schedule has no activations left, the Cron removes its entry.
+Entries keeps reporting the most recent finished entries, last, with the time
+they finished in Entry.Finished.
+
+Calendar exclusions
+
+A Calendar excludes dates, such as public holidays, and periods, such as a
+change freeze. They may be read from an iCalendar file. Excluding, or the
+WithExclusions entry option, skip the activations of a schedule which fall on
+them:
+
+	holidays := cron.NewCalendar()
+	holidays.AddYearly(time.December, 25)
+	holidays.AddDates(freezeStart, freezeEnd)
+	c.AddFunc("0 9 * * 1-5", standup, cron.WithExclusions(holidays))
+
+The dates are checked in the time zone of the schedule.
+
 Time zones
 
 By default, all interpretation and scheduling is done in the machine's local
@@ -178,6 +238,7 @@ to achieve the following effects:
   - Recover any panics from jobs (activated by default)
   - Delay a job's execution if the previous run hasn't completed yet
   - Skip a job's execution if the previous run hasn't completed yet
+  - Retry a failing job with exponential backoff, before its next run is due
   - Log each job's invocations
 
 Install wrappers for all jobs added to a cron using the `cron.WithChain` option:
@@ -192,6 +253,144 @@ Install wrappers for individual jobs by explicitly wrapping them:
 		cron.SkipIfStillRunning(logger),
 	).Then(job)
 
+Concurrency limits
+
+Each run gets its own goroutine, so many jobs firing together, or slow jobs
+piling up, may exhaust shared resources such as database connections. The
+wrappers above only keep a single job from overlapping with itself. To limit
+the runs of all jobs, or of groups of jobs, use:
+
+	c := cron.New(
+		cron.WithMaxConcurrency(8, cron.OverflowWait),
+		cron.WithConcurrencyGroup("db", 2, cron.OverflowDrop),
+	)
+	c.AddFunc("@every 1m", vacuum, cron.InGroup("db"))
+
+Runs exceeding a limit either wait for a slot (OverflowWait), until the Cron is
+stopped, or are dropped (OverflowDrop).
+
+Job dependencies
+
+An entry may run after other entries instead of on its own schedule:
+
+	extract, _ := c.AddFunc("0 2 * * *", extractData)
+	rates, _ := c.AddFunc("0 2 * * *", fetchRates)
+	c.AddFuncAfter([]cron.EntryID{extract, rates}, buildReport)
+
+buildReport runs once both extractData and fetchRates succeeded since its last
+run. Runs which fail or panic do not count, and take back the earlier success of
+their entry. Cron.AddDependency makes an existing entry depend on others; it
+rejects dependencies which would create a cycle.
+
+Persistence
+
+By default the entries only live in memory, so the runs that should have happened
+while the process was down are silently skipped. Configure a JobStore to keep
+each entry's previous and next activation times across restarts:
+
+	c := cron.New(cron.WithJobStore(cron.NewFileJobStore("/var/lib/app/cron.json")))
+	c.AddFunc("@daily", report,
+		cron.WithName("report"),
+		cron.WithMisfirePolicy(cron.MisfireRunOnce))
+
+On start, an entry which missed activations either skips them (MisfireSkip, the
+default), runs once (MisfireRunOnce) or runs once per missed activation
+(MisfireRunAll). The number of runs is saved too, so an entry limited with Times
+never runs more often than its limit, catching up included. Entries are matched
+with their saved state by name, so give a name to every entry that should be
+persisted.
+
+When several replicas run the same schedule, a Locker makes sure each activation
+of an entry runs on only one of them:
+
+	c := cron.New(cron.WithLocker(cron.NewFileLocker("/mnt/shared/cron", 24*time.Hour)))
+
+The replica which takes the lease for an (entry name, activation time) pair runs
+the job, the others skip it. NewMemoryLocker does the same for Cron instances in
+a single process.
+
+Crontab files
+
+A Loader keeps the entries of a Cron in sync with a crontab style file, whose
+lines each give a spec and the name of a job registered with the Loader:
+
+	loader := cron.NewLoader(c, "/etc/app/crontab")
+	loader.RegisterFunc("backup", backup)
+	go loader.Watch(ctx, 10*time.Second)
+
+When the file changes, entries are added, removed or rescheduled to match it,
+and invalid lines are logged while the valid ones still apply.
+
+Contexts and errors
+
+A Job has no way to learn that the Cron is stopping, nor to report that it
+failed. Jobs which need either implement JobWithContext instead:
+
+	c.AddFuncWithContext("@hourly", func(ctx context.Context) error {
+		return sync(ctx)
+	})
+
+The context is cancelled when the Cron is stopped, and a returned error is
+logged and passed to the handler set with WithErrorHandler. Use ContextJob to
+wrap one for the other Cron methods, e.g. to give a single job a deadline:
+
+	c.Schedule(cron.Every(time.Hour), cron.WithTimeout(time.Minute)(cron.ContextJob(job)))
+
+Jobs returning an error may be retried with the Retry wrapper. A func() error
+is turned into such a job by FuncJobWithError:
+
+	retry := cron.Retry(logger, cron.RetryPolicy{MaxAttempts: 5, Backoff: time.Second, Jitter: 0.2})
+	c.AddJob("@hourly", retry(cron.FuncJobWithError(sync)))
+
+Observing runs
+
+An Observer installed with WithObserver is notified whenever an entry is
+scheduled, and whenever its job starts, finishes or panics. The events carry
+the activation time, the actual start time, the duration and the error of the
+run. Each entry also keeps its most recent runs, see WithHistory:
+
+	for _, run := range c.Entry(id).History() {
+		fmt.Println(run.Scheduled, run.Duration, run.Err)
+	}
+
+Describing schedules
+
+Describe returns an English description of a schedule, and NextN its next
+activation times, e.g. to show them to the user before adding an entry:
+
+	sched, _ := cron.ParseStandard("30 2 * * 1-5")
+	fmt.Println(cron.Describe(sched)) // At 02:30 on Monday through Friday
+	fmt.Println(cron.NextN(sched, time.Now(), 5))
+
+Testing
+
+A Cron reads the time from its Clock, which WithClock replaces. The FakeClock
+of the crontest package only moves when advanced, firing the entries due in
+the meantime and waiting for their jobs, so that tests do not have to sleep:
+
+	clock := crontest.NewFakeClock(time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC))
+	c := cron.New(cron.WithClock(clock), cron.WithLocation(time.UTC))
+	c.AddFunc("0 12 * * *", report)
+	c.Start()
+	clock.Advance(24 * time.Hour) // report has run once
+
+The retries of the Retry JobWrapper wait on the same clock, and the leases of
+NewMemoryLocker and NewFileLocker expire on it.
+
+HTTP admin
+
+A Handler serves the entries of a Cron as JSON, and adds, removes and runs
+them at runtime. New entries may only run jobs registered with the Handler:
+
+	h := cron.NewHandler(c)
+	h.RegisterFunc("backup", backup)
+	http.Handle("/cron/", http.StripPrefix("/cron", h))
+
+	curl localhost:8080/cron/
+	curl -X POST localhost:8080/cron/ -d spec='0 3 * * *' -d job=backup
+	curl -X POST localhost:8080/cron/1/run
+	curl -X DELETE localhost:8080/cron/1
+
 Thread safety
 
 Since the Cron service runs concurrently with the calling code, some amount of
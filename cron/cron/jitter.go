@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Jitter returns a Schedule which delays every activation of the schedule by
+// the same offset, less than window. The offset is derived from the key,
+// typically the name of the entry, so that entries sharing a schedule are
+// spread over the window while each one keeps a stable period between runs.
+//
+// Schedules relative to the time they are asked about, such as those of Every,
+// are unaffected: delaying them changes nothing.
+//
+// Entries added with the WithJitter option get their Name, or their ID for
+// entries without a name, as the key.
+func Jitter(schedule Schedule, key string, window time.Duration) Schedule {
+	// The offset is whole seconds, like the activations of the schedules of
+	// this package.
+	n := uint64(window / time.Second)
+	if n == 0 {
+		return schedule
+	}
+	offset := time.Duration(hashKey(key, 0)%n) * time.Second
+	return jitterSchedule{schedule, offset}
+}
+
+// jitterSchedule delays the activations of Schedule by offset.
+type jitterSchedule struct {
+	Schedule
+	offset time.Duration
+}
+
+// Next returns the activation of the schedule following t - offset, delayed by
+// offset. This is the first delayed activation after t.
+func (s jitterSchedule) Next(t time.Time) time.Time {
+	next := s.Schedule.Next(t.Add(-s.offset))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(s.offset)
+}
+
+// hashKey returns a stable hash of the key, varied by salt so that several
+// values derived from the same key are not correlated.
+func hashKey(key string, salt byte) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{salt})
+	// FNV spreads similar keys poorly over the low bits used for small
+	// ranges, so mix the bits as in SplitMix64.
+	x := h.Sum64()
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
@@ -0,0 +1,97 @@
+package cron
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	hourly, _ := ParseStandard("@hourly")
+	from := time.Date(2022, 12, 10, 12, 0, 0, 0, time.UTC)
+
+	offsets := make(map[time.Duration]bool)
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		sched := Jitter(hourly, key, 10*time.Minute)
+		next := sched.Next(from)
+		offset := next.Sub(from)
+		if offset < 0 || offset >= 10*time.Minute || offset%time.Second != 0 {
+			t.Errorf("%s: expected an offset of whole seconds within 10m, got %v", key, offset)
+		}
+		if again := Jitter(hourly, key, 10*time.Minute).Next(from); !again.Equal(next) {
+			t.Errorf("%s: expected a stable offset, got %v and %v", key, next, again)
+		}
+		if following := sched.Next(next); following.Sub(next) != time.Hour {
+			t.Errorf("%s: expected runs an hour apart, got %v and %v", key, next, following)
+		}
+		offsets[offset] = true
+	}
+	if len(offsets) < 4 {
+		t.Errorf("expected the keys to be spread, got %d distinct offsets", len(offsets))
+	}
+
+	if sched := Jitter(hourly, "a", 0); sched != Schedule(hourly) {
+		t.Errorf("expected no jitter without a window, got %v", sched)
+	}
+}
+
+// The offset is relative to the wrapped schedule's activations, so an
+// activation delayed past t is not skipped.
+func TestJitterDelayedActivation(t *testing.T) {
+	daily, _ := ParseStandard("0 0 * * *")
+	sched := jitterSchedule{daily, 5 * time.Minute}
+	from := time.Date(2022, 12, 10, 0, 2, 0, 0, time.UTC)
+	expected := time.Date(2022, 12, 10, 0, 5, 0, 0, time.UTC)
+	if next := sched.Next(from.In(time.Local)); !next.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, next)
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	cron := New(WithParser(NewParser(Minute | Hour | Dom | Month | Dow | Descriptor | Hash)))
+	nexts := make(map[time.Time]bool)
+	for _, name := range []string{"backup", "report", "cleanup", "sync"} {
+		cron.AddFunc("@hourly", func() {}, WithName(name), WithJitter(time.Hour))
+	}
+	cron.Start()
+	defer cron.Stop()
+	for _, entry := range cron.Entries() {
+		if _, ok := entry.Schedule.(jitterSchedule); !ok {
+			t.Fatalf("expected a jittered schedule, got %T", entry.Schedule)
+		}
+		nexts[entry.Next] = true
+	}
+	if len(nexts) < 2 {
+		t.Errorf("expected the entries to be spread, got %v", nexts)
+	}
+}
+
+// H tokens are hashed with the entry's key.
+func TestAddHashed(t *testing.T) {
+	cron := New(WithParser(NewParser(Minute | Hour | Dom | Month | Dow | Hash)))
+	minutesUsed := make(map[uint64]bool)
+	for _, name := range []string{"backup", "report", "cleanup", "sync", "mail", "index"} {
+		if _, err := cron.AddFunc("H * * * *", func() {}, WithName(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id, _ := cron.AddFunc("H * * * *", func() {})
+	for _, entry := range cron.Entries() {
+		minutesUsed[entry.Schedule.(*SpecSchedule).Minute] = true
+	}
+	if len(minutesUsed) < 3 {
+		t.Errorf("expected the entries to be spread, got %d distinct minutes", len(minutesUsed))
+	}
+
+	expected, _ := NewParser(Minute|Hour|Dom|Month|Dow|Hash).ParseHashed("H * * * *", fmt.Sprint(id))
+	if actual := cron.Entry(id).Schedule; actual.(*SpecSchedule).Minute != expected.(*SpecSchedule).Minute {
+		t.Errorf("expected an unnamed entry to be hashed by ID")
+	}
+
+	if _, err := cron.AddFunc("H(0-70) * * * *", func() {}); err == nil {
+		t.Error("expected an error")
+	}
+	if next, _ := cron.AddFunc("* * * * *", func() {}); next != id+1 {
+		t.Errorf("expected the failed spec not to use an ID, got %d after %d", next, id)
+	}
+}
@@ -0,0 +1,83 @@
+package cron
+
+import "context"
+
+// OverflowPolicy decides what happens to a run which would exceed a
+// concurrency limit.
+type OverflowPolicy int
+
+const (
+	// OverflowWait queues the run until a slot frees up, or the Cron stops.
+	OverflowWait OverflowPolicy = iota
+
+	// OverflowDrop skips the run.
+	OverflowDrop
+)
+
+// limiter limits the number of jobs running at once.
+type limiter struct {
+	slots  chan struct{}
+	policy OverflowPolicy
+}
+
+func newLimiter(n int, policy OverflowPolicy) *limiter {
+	if n <= 0 {
+		return nil
+	}
+	return &limiter{slots: make(chan struct{}, n), policy: policy}
+}
+
+// acquire takes a slot. It returns false if the run is dropped, or the context
+// is done before the run gets a slot.
+func (l *limiter) acquire(ctx context.Context) bool {
+	if l.policy == OverflowDrop {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			return false
+		}
+	} else {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	// A slot may free up because the Cron is stopping: stopping wins.
+	if ctx.Err() != nil {
+		l.release()
+		return false
+	}
+	return true
+}
+
+func (l *limiter) release() {
+	<-l.slots
+}
+
+// acquireSlots takes a slot in the entry's group, then one in the global limit.
+// Taking the group's slot first keeps a run waiting for its group from holding
+// a global slot other groups could use. It returns whether the run may start,
+// and a func to free the slots once it is done.
+func (c *Cron) acquireSlots(ctx context.Context, id EntryID, group string) (func(), bool) {
+	var taken []*limiter
+	release := func() {
+		for _, l := range taken {
+			l.release()
+		}
+	}
+	for _, l := range []*limiter{c.groups[group], c.limit} {
+		if l == nil {
+			continue
+		}
+		if !l.acquire(ctx) {
+			release()
+			if ctx.Err() == nil {
+				c.logger.Info("dropped", "entry", id, "group", group)
+			}
+			return nil, false
+		}
+		taken = append(taken, l)
+	}
+	return release, true
+}
@@ -0,0 +1,141 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	if l := newLimiter(0, OverflowWait); l != nil {
+		t.Errorf("expected no limiter without a limit, got %v", l)
+	}
+
+	drop := newLimiter(1, OverflowDrop)
+	if !drop.acquire(context.Background()) {
+		t.Fatal("expected a free slot")
+	}
+	if drop.acquire(context.Background()) {
+		t.Error("expected the run to be dropped")
+	}
+	drop.release()
+	if !drop.acquire(context.Background()) {
+		t.Error("expected the released slot")
+	}
+
+	wait := newLimiter(1, OverflowWait)
+	wait.acquire(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if wait.acquire(ctx) {
+		t.Error("expected the wait to end with the context")
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		wait.release()
+	}()
+	if !wait.acquire(context.Background()) {
+		t.Error("expected the run to get the released slot")
+	}
+}
+
+// concurrencyJob records how many of its runs overlap.
+type concurrencyJob struct {
+	running, max, done int64
+	release            chan struct{}
+}
+
+func (j *concurrencyJob) Run() {
+	n := atomic.AddInt64(&j.running, 1)
+	for {
+		max := atomic.LoadInt64(&j.max)
+		if n <= max || atomic.CompareAndSwapInt64(&j.max, max, n) {
+			break
+		}
+	}
+	<-j.release
+	atomic.AddInt64(&j.running, -1)
+	atomic.AddInt64(&j.done, 1)
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	for _, test := range []struct {
+		policy   OverflowPolicy
+		expected int64
+	}{
+		{OverflowWait, 5},
+		{OverflowDrop, 2},
+	} {
+		job := &concurrencyJob{release: make(chan struct{})}
+		cron := New(WithChain(), WithMaxConcurrency(2, test.policy))
+		for i := 0; i < 5; i++ {
+			id, _ := cron.AddJob("@yearly", job)
+			cron.RunNow(id)
+		}
+		time.Sleep(50 * time.Millisecond) // Let the runs take or wait for a slot.
+		close(job.release)
+		<-cron.Stop().Done()
+
+		if max := atomic.LoadInt64(&job.max); max != 2 {
+			t.Errorf("policy %d: expected at most 2 runs at once, got %d", test.policy, max)
+		}
+		if done := atomic.LoadInt64(&job.done); done != test.expected {
+			t.Errorf("policy %d: expected %d runs, got %d", test.policy, test.expected, done)
+		}
+	}
+}
+
+func TestConcurrencyGroup(t *testing.T) {
+	db := &concurrencyJob{release: make(chan struct{})}
+	other := &concurrencyJob{release: make(chan struct{})}
+	cron := New(WithChain(), WithConcurrencyGroup("db", 1, OverflowWait))
+	for i := 0; i < 3; i++ {
+		id, _ := cron.AddJob("@yearly", db, InGroup("db"))
+		cron.RunNow(id)
+		id, _ = cron.AddJob("@yearly", other)
+		cron.RunNow(id)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if running := atomic.LoadInt64(&other.running); running != 3 {
+		t.Errorf("expected the jobs outside the group to run at once, got %d", running)
+	}
+	close(db.release)
+	close(other.release)
+	<-cron.Stop().Done()
+
+	if max := atomic.LoadInt64(&db.max); max != 1 {
+		t.Errorf("expected one run of the group at once, got %d", max)
+	}
+	if done := atomic.LoadInt64(&db.done); done != 3 {
+		t.Errorf("expected the group's runs to wait their turn, got %d runs", done)
+	}
+}
+
+// Runs waiting for a slot give up when the Cron stops.
+func TestMaxConcurrencyStop(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var calls int64
+	cron := New(WithParser(secondParser), WithChain(), WithMaxConcurrency(1, OverflowWait))
+	cron.AddFuncWithContext("* * * * * ?", func(ctx context.Context) error {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			wg.Done()
+		}
+		<-ctx.Done()
+		return nil
+	})
+	cron.Start()
+	wg.Wait()
+	cron.RunNow(cron.Entries()[0].ID)
+
+	select {
+	case <-cron.Stop().Done():
+	case <-time.After(OneSecond):
+		t.Fatal("expected the waiting run to give up")
+	}
+	if actual := atomic.LoadInt64(&calls); actual != 1 {
+		t.Errorf("expected a single run, got %d", actual)
+	}
+}
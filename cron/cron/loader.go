@@ -0,0 +1,246 @@
+package cron
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Loader loads the entries of a Cron from a crontab style file, and keeps them
+// in sync with it. Each line of the file is a spec followed by the name of a
+// job registered with the Loader:
+//
+//	# Comments and blank lines are ignored.
+//	0 3 * * *      backup
+//	@every 15m     sync
+//
+//	# Sets the time zone of the lines which follow and do not set their own.
+//	CRON_TZ=Asia/Tokyo
+//	30 9 * * 1-5   report
+//	CRON_TZ=UTC 0 0 1 * * invoice
+//
+// The specs are parsed with the Cron's parser, so their format follows its
+// options. The job name identifies the entry, which gets it as its Name: a job
+// may only appear once in the file.
+type Loader struct {
+	cron *Cron
+	path string
+
+	mu      sync.Mutex
+	jobs    map[string]Job
+	entries map[string]loadedEntry // job name -> entry added by the Loader
+}
+
+// loadedEntry is an entry added by the Loader.
+type loadedEntry struct {
+	id   EntryID
+	spec string
+}
+
+// LineError reports an invalid line of a crontab file.
+type LineError struct {
+	Line int    // line number, starting at 1
+	Text string // the line
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// NewLoader returns a Loader of the crontab file at path into the Cron.
+func NewLoader(c *Cron, path string) *Loader {
+	return &Loader{
+		cron:    c,
+		path:    path,
+		jobs:    make(map[string]Job),
+		entries: make(map[string]loadedEntry),
+	}
+}
+
+// Register makes the job available to the lines of the file, under the name.
+func (l *Loader) Register(name string, job Job) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jobs[name] = job
+}
+
+// RegisterFunc makes the func available to the lines of the file, under the
+// name.
+func (l *Loader) RegisterFunc(name string, cmd func()) {
+	l.Register(name, FuncJob(cmd))
+}
+
+// Load reads the file and reconciles the entries of the Cron with it: entries
+// are added for new lines, removed for deleted lines and replaced when their
+// spec changed. Entries of unchanged lines keep running undisturbed. The Cron
+// may be running or not.
+//
+// Invalid lines are returned, and skipped: the entry loaded from an earlier
+// valid version of the line, if any, is kept. The error is only set if the
+// file could not be read, in which case nothing changes.
+func (l *Loader) Load() ([]*LineError, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lines, invalid, err := l.parse(f)
+	if err != nil {
+		return nil, err
+	}
+	l.reconcile(lines, invalid)
+	return invalid, nil
+}
+
+// crontabLine is a valid line of the file.
+type crontabLine struct {
+	name, spec string
+}
+
+// parse reads the valid lines of the file, and the errors of the others.
+func (l *Loader) parse(r io.Reader) ([]crontabLine, []*LineError, error) {
+	var (
+		lines   []crontabLine
+		invalid []*LineError
+		seen    = make(map[string]bool)
+		zone    string // set by a lone CRON_TZ line
+	)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		fields := strings.Fields(text)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		fail := func(format string, args ...interface{}) {
+			invalid = append(invalid, &LineError{Line: n, Text: text, Err: fmt.Errorf(format, args...)})
+		}
+
+		if len(fields) == 1 && strings.Contains(fields[0], "=") {
+			eq := strings.Index(fields[0], "=")
+			if name := fields[0][:eq]; name != "CRON_TZ" && name != "TZ" {
+				fail("unsupported variable: %s", name)
+				continue
+			}
+			if _, err := time.LoadLocation(fields[0][eq+1:]); err != nil {
+				fail("provided bad location %s: %v", fields[0][eq+1:], err)
+				continue
+			}
+			zone = fields[0][eq+1:]
+			continue
+		}
+
+		if len(fields) < 2 {
+			fail("expected a spec and a job name")
+			continue
+		}
+		name := fields[len(fields)-1]
+		spec := strings.Join(fields[:len(fields)-1], " ")
+		if zone != "" && !strings.HasPrefix(spec, "TZ=") && !strings.HasPrefix(spec, "CRON_TZ=") {
+			spec = "CRON_TZ=" + zone + " " + spec
+		}
+		if seen[name] {
+			fail("job %s already scheduled", name)
+			continue
+		}
+		seen[name] = true
+		if _, ok := l.jobs[name]; !ok {
+			fail("unknown job: %s", name)
+			continue
+		}
+		if _, err := l.cron.parse(spec, name); err != nil {
+			fail("%v", err)
+			continue
+		}
+		lines = append(lines, crontabLine{name, spec})
+	}
+	return lines, invalid, scanner.Err()
+}
+
+// reconcile updates the entries of the Cron to match the valid lines. Entries
+// of invalid lines are left alone.
+func (l *Loader) reconcile(lines []crontabLine, invalid []*LineError) {
+	keep := make(map[string]bool)
+	for _, line := range lines {
+		keep[line.name] = true
+	}
+	for _, e := range invalid {
+		if fields := strings.Fields(e.Text); len(fields) > 1 {
+			keep[fields[len(fields)-1]] = true
+		}
+	}
+	for name, entry := range l.entries {
+		if !keep[name] {
+			l.cron.Remove(entry.id)
+			delete(l.entries, name)
+			l.cron.logger.Info("unloaded", "entry", entry.id, "name", name)
+		}
+	}
+
+	for _, line := range lines {
+		entry, ok := l.entries[line.name]
+		if ok && entry.spec == line.spec {
+			continue
+		}
+		if ok {
+			l.cron.Remove(entry.id)
+		}
+		id, err := l.cron.AddJob(line.spec, l.jobs[line.name], WithName(line.name))
+		if err != nil {
+			// Already parsed successfully: the name is used by an entry
+			// which was not added by the Loader.
+			delete(l.entries, line.name)
+			l.cron.logger.Error(err, "load", "name", line.name, "spec", line.spec)
+			continue
+		}
+		l.entries[line.name] = loadedEntry{id: id, spec: line.spec}
+		l.cron.logger.Info("loaded", "entry", id, "name", line.name, "spec", line.spec)
+	}
+}
+
+// Watch loads the file whenever it changes, checking every interval, until the
+// context is done. Errors and invalid lines are logged with the Cron's logger.
+// The file is loaded when Watch starts.
+func (l *Loader) Watch(ctx context.Context, interval time.Duration) {
+	var last os.FileInfo
+	load := func() {
+		info, err := os.Stat(l.path)
+		if err != nil {
+			l.cron.logger.Error(err, "load", "path", l.path)
+			return
+		}
+		if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+			return
+		}
+		invalid, err := l.Load()
+		if err != nil {
+			l.cron.logger.Error(err, "load", "path", l.path)
+			return
+		}
+		last = info
+		for _, e := range invalid {
+			l.cron.logger.Error(e.Err, "invalid line", "path", l.path, "line", e.Line)
+		}
+	}
+
+	load()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			load()
+		}
+	}
+}
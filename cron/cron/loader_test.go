@@ -0,0 +1,168 @@
+package cron
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeCrontab(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestLoader(t *testing.T, cron *Cron) (*Loader, string) {
+	path := filepath.Join(t.TempDir(), "crontab")
+	loader := NewLoader(cron, path)
+	for _, name := range []string{"backup", "report", "sync"} {
+		loader.RegisterFunc(name, func() {})
+	}
+	return loader, path
+}
+
+// loadedSpecs returns the entries of the Cron by name.
+func loadedSpecs(cron *Cron) map[string]Entry {
+	entries := make(map[string]Entry)
+	for _, e := range cron.Entries() {
+		entries[e.Name] = e
+	}
+	return entries
+}
+
+func TestLoader(t *testing.T) {
+	for _, running := range []bool{false, true} {
+		cron := New(WithLogger(DiscardLogger))
+		if running {
+			cron.Start()
+		}
+		loader, path := newTestLoader(t, cron)
+
+		writeCrontab(t, path,
+			"# nightly jobs",
+			"0 3 * * *  backup",
+			"",
+			"CRON_TZ=Asia/Tokyo",
+			"30 9 * * 1-5  report",
+			"CRON_TZ=UTC @every 15m sync",
+		)
+		if invalid, err := loader.Load(); err != nil || len(invalid) != 0 {
+			t.Fatalf("expected the file to load, got %v, %v", invalid, err)
+		}
+		entries := loadedSpecs(cron)
+		if len(entries) != 3 {
+			t.Fatalf("expected 3 entries, got %v", entries)
+		}
+		if spec := entries["report"].Spec; spec != "CRON_TZ=Asia/Tokyo 30 9 * * 1-5" {
+			t.Errorf("expected the time zone of the file, got %q", spec)
+		}
+		if spec := entries["sync"].Spec; spec != "CRON_TZ=UTC @every 15m" {
+			t.Errorf("expected the time zone of the line, got %q", spec)
+		}
+
+		writeCrontab(t, path,
+			"0 4 * * *  backup",
+			"CRON_TZ=Asia/Tokyo",
+			"30 9 * * 1-5  report",
+		)
+		if invalid, err := loader.Load(); err != nil || len(invalid) != 0 {
+			t.Fatalf("expected the file to load, got %v, %v", invalid, err)
+		}
+		updated := loadedSpecs(cron)
+		if len(updated) != 2 {
+			t.Fatalf("expected the sync entry to be removed, got %v", updated)
+		}
+		if updated["report"].ID != entries["report"].ID {
+			t.Errorf("expected the unchanged entry to be kept")
+		}
+		if e := updated["backup"]; e.ID == entries["backup"].ID || e.Spec != "0 4 * * *" {
+			t.Errorf("expected the changed entry to be rescheduled, got %+v", e)
+		}
+		cron.Stop()
+	}
+}
+
+func TestLoaderInvalidLines(t *testing.T) {
+	cron := New(WithLogger(DiscardLogger))
+	loader, path := newTestLoader(t, cron)
+	writeCrontab(t, path, "0 3 * * *  backup", "@hourly report")
+	loader.Load()
+	backup := loadedSpecs(cron)["backup"]
+
+	writeCrontab(t, path,
+		"0 3 * * * * backup",
+		"@hourly report",
+		"@hourly report",
+		"@hourly clean",
+		"@hourly",
+		"PATH=/bin",
+		"CRON_TZ=Nowhere/Special",
+		"@daily sync",
+	)
+	invalid, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[int]string{
+		1: "expected exactly 5 fields",
+		3: "job report already scheduled",
+		4: "unknown job: clean",
+		5: "expected a spec and a job name",
+		6: "unsupported variable: PATH",
+		7: "provided bad location",
+	}
+	if len(invalid) != len(expected) {
+		t.Fatalf("expected %d invalid lines, got %v", len(expected), invalid)
+	}
+	for _, e := range invalid {
+		if !strings.Contains(e.Error(), expected[e.Line]) {
+			t.Errorf("line %d: expected %q, got %v", e.Line, expected[e.Line], e)
+		}
+	}
+
+	// The valid lines are loaded, the invalid one keeps its earlier entry.
+	entries := loadedSpecs(cron)
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries, got %v", entries)
+	}
+	if e := entries["backup"]; e.ID != backup.ID || e.Spec != backup.Spec {
+		t.Errorf("expected the earlier backup entry to be kept, got %+v", e)
+	}
+
+	if _, err := NewLoader(cron, filepath.Join(t.TempDir(), "missing")).Load(); !os.IsNotExist(err) {
+		t.Errorf("expected the missing file to be reported, got %v", err)
+	}
+}
+
+func TestLoaderWatch(t *testing.T) {
+	cron := New(WithLogger(DiscardLogger))
+	cron.Start()
+	defer cron.Stop()
+	loader, path := newTestLoader(t, cron)
+	writeCrontab(t, path, "@hourly backup")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loader.Watch(ctx, 10*time.Millisecond)
+
+	waitFor := func(count int) {
+		t.Helper()
+		for deadline := time.Now().Add(OneSecond); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if len(cron.Entries()) == count {
+				return
+			}
+		}
+		t.Fatalf("expected %d entries, got %v", count, cron.Entries())
+	}
+	waitFor(1)
+
+	writeCrontab(t, path, "@hourly backup", "@daily report")
+	// Make sure the modification time changes on coarse file systems.
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	waitFor(2)
+}
@@ -0,0 +1,163 @@
+package cron
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Locker hands out leases on entry activations. When several Cron instances
+// (e.g. replicas of the same service) share a schedule and a Locker, only the
+// instance holding the lease for an activation runs its job.
+//
+// Activations are identified by the entry's Name, or its ID for entries
+// without a name, and the time the activation was scheduled for. Replicas
+// therefore have to agree on both, which holds for spec based schedules but
+// not for "@every" ones, whose activations depend on when each replica started.
+type Locker interface {
+	// Lock tries to take the lease for the activation of the entry identified
+	// by key at the scheduled time. It returns false if the lease is held
+	// by someone else. Leases are never released: the activation has happened
+	// once it was granted.
+	Lock(key string, scheduled time.Time) (bool, error)
+}
+
+// clockLocker is implemented by the Lockers of this package, whose leases
+// expire on the Clock of the Cron taking them.
+type clockLocker interface {
+	// lockAt is Lock, now being the current time of the Cron.
+	lockAt(key string, scheduled, now time.Time) (bool, error)
+}
+
+// acquireLease reports whether the activation may run. Without a Locker every
+// activation may run. If the Locker fails, the activation is skipped rather
+// than risk running it twice.
+func (c *Cron) acquireLease(id EntryID, key string, scheduled time.Time) bool {
+	if c.locker == nil {
+		return true
+	}
+	var (
+		ok  bool
+		err error
+	)
+	if l, isClockLocker := c.locker.(clockLocker); isClockLocker {
+		ok, err = l.lockAt(key, scheduled, c.now())
+	} else {
+		ok, err = c.locker.Lock(key, scheduled)
+	}
+	if err != nil {
+		c.logger.Error(err, "lock", "entry", id, "scheduled", scheduled)
+		return false
+	}
+	if !ok {
+		c.logger.Info("locked", "entry", id, "scheduled", scheduled)
+	}
+	return ok
+}
+
+// MemoryLocker is a Locker for Cron instances running in the same process.
+type MemoryLocker struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	leases map[string]time.Time // lease name -> scheduled time
+}
+
+// NewMemoryLocker returns a Locker which forgets leases once their scheduled
+// time is more than ttl in the past. The time is read from the Clock of the
+// Cron taking the lease, or from DefaultClock when Lock is called directly.
+func NewMemoryLocker(ttl time.Duration) *MemoryLocker {
+	return &MemoryLocker{
+		ttl:    ttl,
+		leases: make(map[string]time.Time),
+	}
+}
+
+// Lock takes the lease unless it was taken before.
+func (l *MemoryLocker) Lock(key string, scheduled time.Time) (bool, error) {
+	return l.lockAt(key, scheduled, DefaultClock.Now())
+}
+
+func (l *MemoryLocker) lockAt(key string, scheduled, now time.Time) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expired := now.Add(-l.ttl)
+	for name, t := range l.leases {
+		if t.Before(expired) {
+			delete(l.leases, name)
+		}
+	}
+
+	name := leaseName(key, scheduled)
+	if _, ok := l.leases[name]; ok {
+		return false, nil
+	}
+	l.leases[name] = scheduled
+	return true, nil
+}
+
+// FileLocker is a Locker which takes a lease by exclusively creating a file in
+// a shared directory, e.g. on a volume mounted by every replica.
+type FileLocker struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewFileLocker returns a Locker which keeps its lease files in dir. The lease
+// files of activations scheduled more than ttl in the past are removed. The
+// time is read from the Clock of the Cron taking the lease, or from
+// DefaultClock when Lock is called directly.
+func NewFileLocker(dir string, ttl time.Duration) *FileLocker {
+	return &FileLocker{dir: dir, ttl: ttl}
+}
+
+// Lock creates the lease file, failing if it already exists.
+func (l *FileLocker) Lock(key string, scheduled time.Time) (bool, error) {
+	return l.lockAt(key, scheduled, DefaultClock.Now())
+}
+
+func (l *FileLocker) lockAt(key string, scheduled, now time.Time) (bool, error) {
+	l.removeExpired(key, now)
+	f, err := os.OpenFile(filepath.Join(l.dir, leaseName(key, scheduled)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	fmt.Fprintf(f, "%d\n", os.Getpid())
+	return true, f.Close()
+}
+
+// removeExpired removes the key's lease files whose activation was scheduled
+// more than the ttl before now. Errors are ignored: another replica may be
+// removing the same files.
+func (l *FileLocker) removeExpired(key string, now time.Time) {
+	files, err := os.ReadDir(l.dir)
+	if err != nil {
+		return
+	}
+	prefix := url.PathEscape(key) + ","
+	expired := now.Add(-l.ttl)
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".lock") {
+			continue
+		}
+		// 按文件名里面的 scheduled time 判断，而不是文件的 ModTime，这样才跟着 Cron 的 Clock 走
+		unix, err := strconv.ParseInt(name[len(prefix):len(name)-len(".lock")], 10, 64)
+		if err == nil && time.Unix(unix, 0).Before(expired) {
+			os.Remove(filepath.Join(l.dir, name))
+		}
+	}
+}
+
+// leaseName returns a file name safe identifier of the activation.
+func leaseName(key string, scheduled time.Time) string {
+	return fmt.Sprintf("%s,%d.lock", url.PathEscape(key), scheduled.Unix())
+}
@@ -0,0 +1,64 @@
+package cron
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testLocker(t *testing.T, locker Locker) {
+	scheduled := time.Now().Truncate(time.Second)
+
+	if ok, err := locker.Lock("backup", scheduled); !ok || err != nil {
+		t.Fatalf("expected the first lock to succeed, got %v, %v", ok, err)
+	}
+	if ok, err := locker.Lock("backup", scheduled); ok || err != nil {
+		t.Errorf("expected the second lock to fail, got %v, %v", ok, err)
+	}
+	if ok, err := locker.Lock("backup", scheduled.Add(time.Second)); !ok || err != nil {
+		t.Errorf("expected the lock of the next activation to succeed, got %v, %v", ok, err)
+	}
+	if ok, err := locker.Lock("report/daily", scheduled); !ok || err != nil {
+		t.Errorf("expected the lock of another entry to succeed, got %v, %v", ok, err)
+	}
+}
+
+func TestMemoryLocker(t *testing.T) {
+	testLocker(t, NewMemoryLocker(time.Hour))
+}
+
+func TestFileLocker(t *testing.T) {
+	testLocker(t, NewFileLocker(t.TempDir(), time.Hour))
+}
+
+func TestLockerExpiry(t *testing.T) {
+	scheduled := time.Now().Add(-time.Hour)
+	lockers := map[string]Locker{
+		"memory": NewMemoryLocker(time.Minute),
+		"file":   NewFileLocker(t.TempDir(), 0),
+	}
+	for name, locker := range lockers {
+		locker.Lock("backup", scheduled)
+		locker.Lock("other", time.Now())
+		if ok, _ := locker.Lock("backup", scheduled); !ok {
+			t.Errorf("%s: expected the expired lease to be taken again", name)
+		}
+	}
+}
+
+// Two Crons sharing a Locker run each activation only once.
+func TestLockerSharedBetweenCrons(t *testing.T) {
+	var calls int64
+	locker := NewMemoryLocker(time.Minute)
+	for i := 0; i < 2; i++ {
+		cron := New(WithParser(secondParser), WithChain(), WithLocker(locker))
+		cron.AddFunc("* * * * * ?", func() { atomic.AddInt64(&calls, 1) }, WithName("job"))
+		cron.Start()
+		defer cron.Stop()
+	}
+
+	<-time.After(OneSecond)
+	if actual := atomic.LoadInt64(&calls); actual != 1 {
+		t.Errorf("called %d times, expected 1", actual)
+	}
+}
@@ -0,0 +1,172 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of recent runs kept per entry, unless
+// changed with WithHistory.
+const DefaultHistorySize = 10
+
+// JobEvent describes an event in the life of an entry. Fields which do not
+// apply to the event are left zero.
+type JobEvent struct {
+	// Entry and Name identify the entry.
+	Entry EntryID
+	Name  string
+
+	// Scheduled is the activation time of the run, or for JobScheduled the
+	// time of the next activation.
+	Scheduled time.Time
+
+	// Started is the time the job actually started, which may be later than
+	// Scheduled when the Cron is busy or a JobWrapper delayed the run.
+	Started time.Time
+
+	// Duration is how long the job ran.
+	Duration time.Duration
+
+	// Err is the error returned by the job, or the recovered panic.
+	Err error
+}
+
+// Observer is notified of every job run. JobScheduled is called on the
+// scheduler's goroutine, the others on the job's goroutine, so they must not
+// block for long.
+type Observer interface {
+	// JobScheduled is called whenever an entry's next activation is computed.
+	JobScheduled(JobEvent)
+
+	// JobStarted is called right before the job runs.
+	JobStarted(JobEvent)
+
+	// JobFinished is called after the job returned, with its error if any.
+	JobFinished(JobEvent)
+
+	// JobPanicked is called after the job panicked, whether or not the panic
+	// was recovered by the Recover JobWrapper.
+	JobPanicked(JobEvent)
+}
+
+// RunRecord records a past run of an entry's job.
+type RunRecord struct {
+	Scheduled time.Time
+	Started   time.Time
+	Duration  time.Duration
+	Err       error
+	Panicked  bool
+}
+
+// runHistory is a ring buffer of an entry's most recent runs. It is shared by
+// the entry and its snapshots, so it has its own lock.
+type runHistory struct {
+	mu   sync.Mutex
+	runs []RunRecord
+	next int // where the next run is recorded, once runs is full
+}
+
+func newRunHistory(size int) *runHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &runHistory{runs: make([]RunRecord, 0, size)}
+}
+
+func (h *runHistory) add(r RunRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.runs) < cap(h.runs) {
+		h.runs = append(h.runs, r)
+		return
+	}
+	h.runs[h.next] = r
+	h.next = (h.next + 1) % len(h.runs)
+}
+
+// History returns the entry's most recent runs, oldest first. It is empty
+// unless the Cron keeps a history (see WithHistory).
+func (e Entry) History() []RunRecord {
+	h := e.history
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	runs := make([]RunRecord, 0, len(h.runs))
+	runs = append(runs, h.runs[h.next:]...)
+	return append(runs, h.runs[:h.next]...)
+}
+
+// panicError is returned by the Recover JobWrapper for a recovered panic, so
+// that it is still reported as one.
+type panicError struct {
+	value interface{}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// execute runs the job for the activation described by ev, reporting the run
+// to the observers and the entry's history. It returns true if the job
+// succeeded.
+func (c *Cron) execute(ctx context.Context, j Job, h *runHistory, ev JobEvent) bool {
+	ev.Started = c.now()
+	for _, o := range c.observers {
+		o.JobStarted(ev)
+	}
+	defer func() {
+		// Not recovered by the chain: report it, then let it crash as usual.
+		if r := recover(); r != nil {
+			ev.Duration = c.now().Sub(ev.Started)
+			ev.Err = &panicError{r}
+			c.finished(h, ev, true)
+			panic(r)
+		}
+	}()
+
+	err := runJob(ctx, j)
+	ev.Duration = c.now().Sub(ev.Started)
+	ev.Err = err
+	var pe *panicError
+	if errors.As(err, &pe) {
+		c.finished(h, ev, true) // already logged by Recover
+		return false
+	}
+	if err != nil {
+		c.handleError(ev.Entry, err)
+	}
+	c.finished(h, ev, false)
+	return err == nil
+}
+
+// finished records the run and notifies the observers.
+func (c *Cron) finished(h *runHistory, ev JobEvent, panicked bool) {
+	if h != nil {
+		h.add(RunRecord{
+			Scheduled: ev.Scheduled,
+			Started:   ev.Started,
+			Duration:  ev.Duration,
+			Err:       ev.Err,
+			Panicked:  panicked,
+		})
+	}
+	for _, o := range c.observers {
+		if panicked {
+			o.JobPanicked(ev)
+		} else {
+			o.JobFinished(ev)
+		}
+	}
+}
+
+// scheduled notifies the observers of the entry's next activation.
+func (c *Cron) scheduled(e *Entry) {
+	for _, o := range c.observers {
+		o.JobScheduled(JobEvent{Entry: e.ID, Name: e.Name, Scheduled: e.Next})
+	}
+}
@@ -0,0 +1,150 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingObserver records the events it is notified of.
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+	last   map[string]JobEvent
+	done   chan struct{}
+}
+
+func newRecordingObserver() *recordingObserver {
+	return &recordingObserver{last: make(map[string]JobEvent), done: make(chan struct{}, 10)}
+}
+
+func (o *recordingObserver) record(kind string, ev JobEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, kind)
+	o.last[kind] = ev
+}
+
+func (o *recordingObserver) JobScheduled(ev JobEvent) { o.record("scheduled", ev) }
+func (o *recordingObserver) JobStarted(ev JobEvent)   { o.record("started", ev) }
+func (o *recordingObserver) JobFinished(ev JobEvent) {
+	o.record("finished", ev)
+	o.done <- struct{}{}
+}
+func (o *recordingObserver) JobPanicked(ev JobEvent) {
+	o.record("panicked", ev)
+	o.done <- struct{}{}
+}
+
+func (o *recordingObserver) Last(kind string) (JobEvent, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	ev, ok := o.last[kind]
+	return ev, ok
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("finished with error", func(t *testing.T) {
+		obs := newRecordingObserver()
+		cron := New(WithParser(secondParser), WithChain(), WithLogger(DiscardLogger), WithObserver(obs))
+		id, _ := cron.AddFuncWithContext("* * * * * ?", func(context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return errors.New("YOLO")
+		}, WithName("failing"))
+		cron.Start()
+		defer cron.Stop()
+
+		select {
+		case <-time.After(OneSecond):
+			t.Fatal("expected the job to finish")
+		case <-obs.done:
+		}
+
+		if ev, ok := obs.Last("scheduled"); !ok || ev.Entry != id || ev.Scheduled.IsZero() {
+			t.Errorf("expected the entry to be scheduled, got %+v", ev)
+		}
+		started, _ := obs.Last("started")
+		finished, _ := obs.Last("finished")
+		if finished.Entry != id || finished.Name != "failing" {
+			t.Errorf("expected entry %d, got %+v", id, finished)
+		}
+		if finished.Err == nil || finished.Err.Error() != "YOLO" {
+			t.Errorf("expected the job's error, got %v", finished.Err)
+		}
+		if finished.Duration < 10*time.Millisecond {
+			t.Errorf("expected a duration of at least 10ms, got %v", finished.Duration)
+		}
+		if finished.Started.Before(finished.Scheduled) || !started.Started.Equal(finished.Started) {
+			t.Errorf("expected the job to start after %v, got %v", finished.Scheduled, finished.Started)
+		}
+	})
+
+	t.Run("panic recovered by the chain", func(t *testing.T) {
+		obs := newRecordingObserver()
+		cron := New(WithParser(secondParser), WithChain(Recover(DiscardLogger)), WithObserver(obs))
+		cron.AddFunc("* * * * * ?", func() { panic("YOLO") })
+		cron.Start()
+		defer cron.Stop()
+
+		select {
+		case <-time.After(OneSecond):
+			t.Fatal("expected the job to panic")
+		case <-obs.done:
+		}
+		if ev, ok := obs.Last("panicked"); !ok || ev.Err == nil || ev.Err.Error() != "panic: YOLO" {
+			t.Errorf("expected the panic to be observed, got %+v", ev)
+		}
+		if _, ok := obs.Last("finished"); ok {
+			t.Error("expected the job not to finish")
+		}
+	})
+}
+
+func TestRunHistory(t *testing.T) {
+	h := newRunHistory(3)
+	entry := Entry{history: h}
+	if runs := entry.History(); len(runs) != 0 {
+		t.Errorf("expected no runs, got %v", runs)
+	}
+
+	base := time.Date(2022, 12, 10, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		h.add(RunRecord{Scheduled: base.Add(time.Duration(i) * time.Minute)})
+	}
+	runs := entry.History()
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+	for i, run := range runs {
+		if expected := base.Add(time.Duration(i+2) * time.Minute); !run.Scheduled.Equal(expected) {
+			t.Errorf("run %d: expected %v, got %v", i, expected, run.Scheduled)
+		}
+	}
+
+	if runs := (Entry{history: newRunHistory(0)}).History(); runs != nil {
+		t.Errorf("expected no history, got %v", runs)
+	}
+}
+
+func TestEntryHistory(t *testing.T) {
+	obs := newRecordingObserver()
+	cron := New(WithParser(secondParser), WithChain(), WithObserver(obs))
+	id, _ := cron.AddFunc("* * * * * ?", func() {})
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected the job to finish")
+	case <-obs.done:
+	}
+	runs := cron.Entry(id).History()
+	if len(runs) != 1 || runs[0].Err != nil || runs[0].Panicked {
+		t.Fatalf("expected a successful run, got %+v", runs)
+	}
+	if prev := cron.Entry(id).Prev; !runs[0].Scheduled.Equal(prev) {
+		t.Errorf("expected the run scheduled at %v, got %v", prev, runs[0].Scheduled)
+	}
+}
@@ -22,6 +22,17 @@ func WithSeconds() Option {
 	))
 }
 
+// WithClock overrides the source of time of the cron instance, e.g. with a fake
+// clock in tests. See Clock and Tracker.
+func WithClock(clock Clock) Option {
+	return func(c *Cron) {
+		c.clock = clock
+		if t, ok := clock.(Tracker); ok {
+			t.Track(func() { c.Entries() })
+		}
+	}
+}
+
 // WithParser overrides the parser used for interpreting job schedules.
 func WithParser(p ScheduleParser) Option {
 	return func(c *Cron) {
@@ -43,3 +54,106 @@ func WithLogger(logger Logger) Option {
 		c.logger = logger
 	}
 }
+
+// WithJobStore persists the entries in the given store. On start, every entry
+// restores its Prev time from the store and catches up on the runs it missed
+// according to its MisfirePolicy.
+func WithJobStore(store JobStore) Option {
+	return func(c *Cron) {
+		c.store = store
+	}
+}
+
+// WithLocker makes every activation take a lease from the given Locker before
+// its job runs. Cron instances sharing the Locker then run each activation of
+// an entry only once between them.
+func WithLocker(locker Locker) Option {
+	return func(c *Cron) {
+		c.locker = locker
+	}
+}
+
+// WithErrorHandler calls the handler with the error returned by a job, after
+// the error is logged. The handler runs on the job's goroutine.
+func WithErrorHandler(handler func(id EntryID, err error)) Option {
+	return func(c *Cron) {
+		c.errorHandler = handler
+	}
+}
+
+// WithObserver adds observers which are notified of every job run.
+func WithObserver(observers ...Observer) Option {
+	return func(c *Cron) {
+		c.observers = append(c.observers, observers...)
+	}
+}
+
+// WithHistory sets the number of recent runs kept per entry. Zero disables the
+// history.
+func WithHistory(size int) Option {
+	return func(c *Cron) {
+		c.historySize = size
+	}
+}
+
+// WithMaxConcurrency limits the number of jobs running at once to n. The
+// policy decides whether the runs exceeding the limit wait for a running job
+// to finish, or are dropped.
+func WithMaxConcurrency(n int, policy OverflowPolicy) Option {
+	return func(c *Cron) {
+		c.limit = newLimiter(n, policy)
+	}
+}
+
+// WithConcurrencyGroup limits the number of jobs of the named group running at
+// once to n, in addition to the limit set with WithMaxConcurrency. Entries join
+// the group with the InGroup entry option.
+func WithConcurrencyGroup(name string, n int, policy OverflowPolicy) Option {
+	return func(c *Cron) {
+		if c.groups == nil {
+			c.groups = make(map[string]*limiter)
+		}
+		c.groups[name] = newLimiter(n, policy)
+	}
+}
+
+// EntryOption represents a modification to the default behavior of an Entry.
+type EntryOption func(*Entry)
+
+// WithName gives the entry a name which identifies it across restarts.
+func WithName(name string) EntryOption {
+	return func(e *Entry) {
+		e.Name = name
+	}
+}
+
+// InGroup puts the entry's runs in the named concurrency group. A group
+// without a limit set with WithConcurrencyGroup is unlimited.
+func InGroup(name string) EntryOption {
+	return func(e *Entry) {
+		e.Group = name
+	}
+}
+
+// WithJitter delays every activation of the entry by the same offset, less
+// than window, derived from the entry's Name or ID. See Jitter.
+func WithJitter(window time.Duration) EntryOption {
+	return func(e *Entry) {
+		e.jitter = window
+	}
+}
+
+// WithExclusions skips the activations of the entry excluded by the calendar.
+// See Excluding.
+func WithExclusions(calendar *Calendar) EntryOption {
+	return func(e *Entry) {
+		e.calendar = calendar
+	}
+}
+
+// WithMisfirePolicy sets how the entry catches up on missed runs.
+func WithMisfirePolicy(policy MisfirePolicy) EntryOption {
+	return func(e *Entry) {
+		e.MisfirePolicy = policy
+	}
+}
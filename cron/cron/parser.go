@@ -24,6 +24,10 @@ const (
 	Dow                                    // Day of week field, default *
 	DowOptional                            // Optional day of week field, default *
 	Descriptor                             // Allow descriptors such as @monthly, @weekly, etc.
+	LastDay                                // Allow "L" in Dom ("L", "L-3") and Dow ("5L", last Friday of the month)
+	NearestWeekday                         // Allow "W" in Dom ("15W", "LW" with LastDay)
+	NthDayOfWeek                           // Allow "#" in Dow ("5#3", third Friday of the month)
+	Hash                                   // Allow Jenkins style "H" tokens ("H", "H(0-29)", "H/15")
 )
 
 var places = []ParseOption{
@@ -85,7 +89,27 @@ func NewParser(options ParseOption) Parser {
 // Parse returns a new crontab schedule representing the given spec.
 // It returns a descriptive error if the spec is not valid.
 // It accepts crontab specs and features configured by NewParser.
+//
+// H tokens are replaced with the values for an empty key, see ParseHashed.
 func (p Parser) Parse(spec string) (Schedule, error) {
+	return p.ParseHashed(spec, "")
+}
+
+// ParseHashed is like Parse, but replaces each H token of the spec with a value
+// derived from the key, if the parser was created with the Hash option:
+//
+//  H        a value within the field, e.g. "H * * * *" runs hourly at a fixed minute
+//  H(a-b)   a value within a-b
+//  H/n      every n starting from a value below n, like "x/n"
+//  H(a-b)/n every n within a-b, starting from a value below a+n
+//
+// Specs sharing H tokens thus activate at different times for different keys,
+// but always at the same times for the same key. Unless a range is given, H in
+// the day of month field stays within 1-28, so that it is valid in every month.
+//
+// The Cron uses the entry's Name as the key, or its ID for entries without a
+// name.
+func (p Parser) ParseHashed(spec, key string) (Schedule, error) {
 	if len(spec) == 0 {
 		return nil, fmt.Errorf("empty spec string")
 	}
@@ -120,6 +144,25 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 		return nil, err
 	}
 
+	// Replace the H tokens by values.
+	if p.options&Hash > 0 {
+		for i, r := range fieldBounds {
+			if fields[i], err = hashField(fields[i], r, key, byte(i)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Take out the L, W and # expressions, if configured, leaving the rest of
+	// the day fields to getField.
+	var rules dayRules
+	if fields[3], err = rules.parseDom(fields[3], p.options); err != nil {
+		return nil, err
+	}
+	if fields[5], err = rules.parseDow(fields[5], p.options); err != nil {
+		return nil, err
+	}
+
 	field := func(field string, r bounds) uint64 {
 		if err != nil {
 			return 0
@@ -141,7 +184,7 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 		return nil, err
 	}
 
-	return &SpecSchedule{
+	schedule := &SpecSchedule{
 		Second:   second,
 		Minute:   minute,
 		Hour:     hour,
@@ -149,7 +192,175 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 		Month:    month,
 		Dow:      dayofweek,
 		Location: loc,
-	}, nil
+	}
+	if rules != (dayRules{}) {
+		return dayRuleSchedule{schedule, rules}, nil
+	}
+	return schedule, nil
+}
+
+// fieldBounds are the bounds of the fields, in the order of places.
+var fieldBounds = []bounds{seconds, minutes, hours, dom, months, dow}
+
+// hashField replaces the H tokens of the field with values derived from the key
+// and the position of the field.
+func hashField(field string, r bounds, key string, place byte) (string, error) {
+	if !strings.Contains(field, "H") {
+		return field, nil
+	}
+	exprs := strings.Split(field, ",")
+	for i, expr := range exprs {
+		if !strings.HasPrefix(expr, "H") {
+			continue
+		}
+		min, max := r.min, r.max
+		if r.min == dom.min && r.max == dom.max {
+			max = 28
+		}
+		rest := expr[1:]
+		if strings.HasPrefix(rest, "(") {
+			end := strings.Index(rest, ")")
+			if end < 0 {
+				return "", fmt.Errorf("missing closing parenthesis: %s", expr)
+			}
+			lowAndHigh := strings.Split(rest[1:end], "-")
+			if len(lowAndHigh) != 2 {
+				return "", fmt.Errorf("expected a range in parentheses: %s", expr)
+			}
+			var err error
+			if min, err = parseIntOrName(lowAndHigh[0], r.names); err != nil {
+				return "", err
+			}
+			if max, err = parseIntOrName(lowAndHigh[1], r.names); err != nil {
+				return "", err
+			}
+			if min < r.min || max > r.max || min > max {
+				return "", fmt.Errorf("hash range (%d-%d) out of range (%d-%d): %s", min, max, r.min, r.max, expr)
+			}
+			rest = rest[end+1:]
+		}
+
+		hash := hashKey(key, place)
+		switch {
+		case rest == "":
+			exprs[i] = strconv.FormatUint(uint64(min)+hash%uint64(max-min+1), 10)
+		case strings.HasPrefix(rest, "/"):
+			step, err := mustParseInt(rest[1:])
+			if err != nil {
+				return "", err
+			}
+			if step == 0 {
+				return "", fmt.Errorf("step of range should be a positive number: %s", expr)
+			}
+			span := step
+			if span > max-min+1 {
+				span = max - min + 1
+			}
+			start := uint64(min) + hash%uint64(span)
+			exprs[i] = fmt.Sprintf("%d-%d/%d", start, max, step)
+		default:
+			return "", fmt.Errorf("invalid hash expression: %s", expr)
+		}
+	}
+	return strings.Join(exprs, ","), nil
+}
+
+// parseDom takes the L and W expressions allowed by the options out of the day
+// of month field, and returns the remaining expressions.
+func (r *dayRules) parseDom(field string, options ParseOption) (string, error) {
+	if options&(LastDay|NearestWeekday) == 0 {
+		return field, nil
+	}
+	var rest []string
+	for _, expr := range strings.Split(field, ",") {
+		upper := strings.ToUpper(expr)
+		switch {
+		case upper == "LW" && options&LastDay > 0 && options&NearestWeekday > 0:
+			r.lastWeekday = true
+		case strings.HasPrefix(upper, "L") && options&LastDay > 0:
+			var offset uint
+			if upper != "L" {
+				if !strings.HasPrefix(upper, "L-") {
+					return "", fmt.Errorf("invalid last day expression: %s", expr)
+				}
+				var err error
+				if offset, err = mustParseInt(upper[2:]); err != nil {
+					return "", err
+				}
+				if offset > dom.max-1 {
+					return "", fmt.Errorf("last day offset (%d) above maximum (%d): %s", offset, dom.max-1, expr)
+				}
+			}
+			r.lastDom |= 1 << offset
+		case strings.HasSuffix(upper, "W") && options&NearestWeekday > 0:
+			day, err := mustParseInt(upper[:len(upper)-1])
+			if err != nil {
+				return "", err
+			}
+			if day < dom.min || day > dom.max {
+				return "", fmt.Errorf("nearest weekday (%d) out of range (%d-%d): %s", day, dom.min, dom.max, expr)
+			}
+			r.weekdayDom |= 1 << day
+		default:
+			rest = append(rest, expr)
+		}
+	}
+	return strings.Join(rest, ","), nil
+}
+
+// parseDow takes the L and # expressions allowed by the options out of the day
+// of week field, and returns the remaining expressions.
+func (r *dayRules) parseDow(field string, options ParseOption) (string, error) {
+	if options&(LastDay|NthDayOfWeek) == 0 {
+		return field, nil
+	}
+	var rest []string
+	for _, expr := range strings.Split(field, ",") {
+		upper := strings.ToUpper(expr)
+		switch {
+		case upper == "L" && options&LastDay > 0:
+			// Like in Quartz, a lone "L" is the last day of the week.
+			r.lastDow |= 1 << dow.max
+		case strings.HasSuffix(upper, "L") && options&LastDay > 0:
+			day, err := parseDow(expr[:len(expr)-1], expr)
+			if err != nil {
+				return "", err
+			}
+			r.lastDow |= 1 << day
+		case strings.Contains(expr, "#") && options&NthDayOfWeek > 0:
+			parts := strings.Split(expr, "#")
+			if len(parts) != 2 {
+				return "", fmt.Errorf("too many hashes: %s", expr)
+			}
+			day, err := parseDow(parts[0], expr)
+			if err != nil {
+				return "", err
+			}
+			nth, err := mustParseInt(parts[1])
+			if err != nil {
+				return "", err
+			}
+			if nth < 1 || nth > 5 {
+				return "", fmt.Errorf("occurrence (%d) out of range (1-5): %s", nth, expr)
+			}
+			r.nthDow |= 1 << (day*8 + nth)
+		default:
+			rest = append(rest, expr)
+		}
+	}
+	return strings.Join(rest, ","), nil
+}
+
+// parseDow returns the (possibly-named) day of week in expr.
+func parseDow(day, expr string) (uint, error) {
+	d, err := parseIntOrName(day, dow.names)
+	if err != nil {
+		return 0, err
+	}
+	if d > dow.max {
+		return 0, fmt.Errorf("day of week (%d) above maximum (%d): %s", d, dow.max, expr)
+	}
+	return d, nil
 }
 
 // normalizeFields takes a subset set of the time fields and returns the full set
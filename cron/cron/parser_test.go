@@ -1,6 +1,7 @@
 package cron
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -381,3 +382,128 @@ func annual(loc *time.Location) *SpecSchedule {
 		Location: loc,
 	}
 }
+
+func TestParseExtendedErrors(t *testing.T) {
+	var tests = []struct {
+		parser    Parser
+		expr, err string
+	}{
+		{secondParser, "0 0 0 L * ?", "failed to parse int from"},
+		{secondParser, "0 0 0 15W * ?", "failed to parse int from"},
+		{secondParser, "0 0 0 ? * 5#3", "failed to parse int from"},
+		{NewParser(Second | Minute | Hour | Dom | Month | Dow | LastDay), "0 0 0 LW * ?", "invalid last day expression"},
+		{NewParser(Second | Minute | Hour | Dom | Month | Dow | LastDay), "0 0 0 L-31 * ?", "above maximum"},
+		{NewParser(Second | Minute | Hour | Dom | Month | Dow | LastDay), "0 0 0 ? * 7L", "above maximum"},
+		{NewParser(Second | Minute | Hour | Dom | Month | Dow | NearestWeekday), "0 0 0 32W * ?", "out of range"},
+		{NewParser(Second | Minute | Hour | Dom | Month | Dow | NthDayOfWeek), "0 0 0 ? * 5#6", "out of range"},
+		{NewParser(Second | Minute | Hour | Dom | Month | Dow | NthDayOfWeek), "0 0 0 ? * 5#1#2", "too many hashes"},
+	}
+	for _, c := range tests {
+		actual, err := c.parser.Parse(c.expr)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+		if actual != nil {
+			t.Errorf("expected nil schedule on error, got %v", actual)
+		}
+	}
+}
+
+// Only the specs using the L, W and # expressions get a schedule other than
+// a SpecSchedule.
+func TestParseExtendedSchedule(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | LastDay | NearestWeekday | NthDayOfWeek)
+	sched, err := parser.Parse("0 5 * * * ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := every5min(time.Local); !reflect.DeepEqual(sched, expected) {
+		t.Errorf("expected %v, got %v", expected, sched)
+	}
+
+	sched, err = parser.Parse("0 0 0 L * ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, ok := sched.(dayRuleSchedule)
+	if !ok {
+		t.Fatalf("expected a dayRuleSchedule, got %T", sched)
+	}
+	if rules.rules.lastDom != 1 || rules.Dom != 0 {
+		t.Errorf("expected the last day rule alone, got %+v %+v", rules.SpecSchedule, rules.rules)
+	}
+}
+
+func TestParseHashed(t *testing.T) {
+	parser := NewParser(Minute | Hour | Dom | Month | Dow | Hash)
+	parse := func(spec, key string) *SpecSchedule {
+		t.Helper()
+		sched, err := parser.ParseHashed(spec, key)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		return sched.(*SpecSchedule)
+	}
+	ones := func(bits uint64) (n int) {
+		for ; bits > 0; bits &= bits - 1 {
+			n++
+		}
+		return n
+	}
+
+	// The same key always gets the same values, keys differ.
+	minutesUsed := make(map[uint64]bool)
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprint("job-", i)
+		sched := parse("H * * * *", key)
+		if ones(sched.Minute) != 1 || sched.Minute&^all(minutes) != 0 {
+			t.Fatalf("%s: expected a single minute, got %b", key, sched.Minute)
+		}
+		if again := parse("H * * * *", key); again.Minute != sched.Minute {
+			t.Errorf("%s: expected a stable minute, got %b and %b", key, sched.Minute, again.Minute)
+		}
+		minutesUsed[sched.Minute] = true
+	}
+	if len(minutesUsed) < 10 {
+		t.Errorf("expected the keys to be spread, got %d distinct minutes", len(minutesUsed))
+	}
+
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprint("job-", i)
+		if sched := parse("H(0-29) H(9-17) * * H(mon-fri)", key); sched.Minute&^rangeBits("0-29", minutes) != 0 ||
+			sched.Hour&^rangeBits("9-17", hours) != 0 || sched.Dow&^rangeBits("1-5", dow) != 0 {
+			t.Errorf("%s: expected values within the ranges, got %b %b %b", key, sched.Minute, sched.Hour, sched.Dow)
+		}
+		if sched := parse("H/15 * * * *", key); ones(sched.Minute) != 4 || sched.Minute&rangeBits("0-14", minutes) == 0 {
+			t.Errorf("%s: expected 4 minutes starting before 15, got %b", key, sched.Minute)
+		}
+		if sched := parse("0 0 H * *", key); sched.Dom&^rangeBits("1-28", dom) != 0 {
+			t.Errorf("%s: expected a day of month in 1-28, got %b", key, sched.Dom)
+		}
+		if sched := parse("0 0 * * H", key); ones(sched.Dow) != 1 {
+			t.Errorf("%s: expected a single day of week, got %b", key, sched.Dow)
+		}
+	}
+
+	var tests = []struct {
+		parser    Parser
+		expr, err string
+	}{
+		{standardParser, "H * * * *", "failed to parse int from"},
+		{parser, "H(0-29 * * * *", "missing closing parenthesis"},
+		{parser, "H(5) * * * *", "expected a range"},
+		{parser, "H(30-70) * * * *", "out of range"},
+		{parser, "H/0 * * * *", "should be a positive number"},
+		{parser, "Hx * * * *", "invalid hash expression"},
+	}
+	for _, c := range tests {
+		if _, err := c.parser.ParseHashed(c.expr, "job"); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+	}
+}
+
+func rangeBits(expr string, r bounds) uint64 {
+	bits, _ := getRange(expr, r)
+	return bits
+}
@@ -0,0 +1,122 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// FuncJobWithError is a wrapper that turns a func() error into a cron.Job
+// whose error is reported, and may be retried with the Retry JobWrapper.
+type FuncJobWithError func() error
+
+func (f FuncJobWithError) Run() { f() }
+
+func (f FuncJobWithError) runContext(context.Context) error { return f() }
+
+// nextActivationKey is the context key of the next activation of the entry.
+type nextActivationKey struct{}
+
+// NextActivation returns the time the entry of the running job is next
+// scheduled to run, as seen by the JobWithContext and JobWrappers given the
+// context of the run. It returns false if the entry has no next activation,
+// e.g. when a paused entry is run with RunNow.
+func NextActivation(ctx context.Context) (time.Time, bool) {
+	next, ok := ctx.Value(nextActivationKey{}).(time.Time)
+	return next, ok
+}
+
+// RetryPolicy configures the Retry JobWrapper. Zero fields get the defaults
+// noted below.
+type RetryPolicy struct {
+	// MaxAttempts is the number of runs, including the first one, before
+	// giving up. Default: 3.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, which is multiplied by
+	// Multiplier for each further one, up to MaxBackoff. Defaults: 1s, 2,
+	// and no maximum.
+	Backoff    time.Duration
+	Multiplier float64
+	MaxBackoff time.Duration
+
+	// Jitter shortens each delay by a random fraction of up to Jitter, between
+	// 0 and 1, so that failing jobs do not retry in lockstep. Values outside
+	// are clamped. Default: 0.
+	Jitter float64
+}
+
+// Retry runs the job again when it returns an error, waiting longer after
+// each failure as set by the policy. Only jobs which return an error, such as
+// a JobWithContext or a FuncJobWithError, can fail.
+//
+// A retry never overlaps the job's next scheduled run: the job gives up when
+// the retry could not start before it, and the context of a retry is
+// cancelled when the next run is due. Panics are not retried. Retries are
+// logged at Info level, and giving up at Error level.
+//
+// The delays and the next run are measured on the Clock of the Cron running
+// the job, so that retries follow a fake clock set by WithClock. Outside of a
+// Cron, DefaultClock is used.
+func Retry(logger Logger, policy RetryPolicy) JobWrapper {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = time.Second
+	}
+	if policy.Multiplier <= 0 {
+		policy.Multiplier = 2
+	}
+	// Jitter 大于 1 的话 delay 会变成负数
+	if policy.Jitter < 0 {
+		policy.Jitter = 0
+	} else if policy.Jitter > 1 {
+		policy.Jitter = 1
+	}
+	return func(j Job) Job {
+		return contextFuncJob(func(ctx context.Context) error {
+			next, hasNext := NextActivation(ctx)
+			clock := clockOf(ctx)
+			backoff := policy.Backoff
+			for attempt := 1; ; attempt++ {
+				err := runAttempt(ctx, clock, j, attempt, next, hasNext)
+				var pe *panicError
+				if err == nil || errors.As(err, &pe) {
+					return err
+				}
+				if attempt >= policy.MaxAttempts {
+					logger.Error(err, "retry failed", "attempts", attempt)
+					return err
+				}
+
+				delay := backoff - time.Duration(rand.Float64()*policy.Jitter*float64(backoff))
+				if hasNext && !clock.Now().Add(delay).Before(next) {
+					logger.Error(err, "retry abandoned", "attempts", attempt, "next", next)
+					return err
+				}
+				logger.Info("retry", "attempt", attempt, "delay", delay, "error", err)
+				if !sleep(ctx, clock, delay) {
+					return err
+				}
+
+				backoff = time.Duration(float64(backoff) * policy.Multiplier)
+				if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+					backoff = policy.MaxBackoff
+				}
+			}
+		})
+	}
+}
+
+// runAttempt runs the job. Retries get a context cancelled at the entry's
+// next activation.
+func runAttempt(ctx context.Context, clock Clock, j Job, attempt int, next time.Time, hasNext bool) error {
+	if attempt > 1 && hasNext {
+		var cancel context.CancelFunc
+		ctx, cancel = withDeadline(ctx, clock, next)
+		defer cancel()
+	}
+	return runJob(ctx, j)
+}
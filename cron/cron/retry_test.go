@@ -0,0 +1,144 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingJob fails until it ran the given number of times.
+type failingJob struct {
+	failures, runs int
+	deadlines      []time.Time
+}
+
+func (j *failingJob) Run(ctx context.Context) error {
+	j.runs++
+	deadline, _ := ctx.Deadline()
+	j.deadlines = append(j.deadlines, deadline)
+	if j.runs <= j.failures {
+		return errors.New("YOLO")
+	}
+	return nil
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Jitter: 0.5}
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		var buf bytes.Buffer
+		job := &failingJob{failures: 2}
+		err := runJob(context.Background(), Retry(VerbosePrintfLogger(log.New(&buf, "", 0)), policy)(ContextJob(job)))
+		if err != nil || job.runs != 3 {
+			t.Errorf("expected 3 runs and no error, got %d, %v", job.runs, err)
+		}
+		if retries := strings.Count(buf.String(), "retry"); retries != 2 {
+			t.Errorf("expected 2 retries logged, got %q", buf.String())
+		}
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		var buf bytes.Buffer
+		job := &failingJob{failures: 5}
+		err := runJob(context.Background(), Retry(PrintfLogger(log.New(&buf, "", 0)), policy)(ContextJob(job)))
+		if err == nil || job.runs != 3 {
+			t.Errorf("expected 3 runs and an error, got %d, %v", job.runs, err)
+		}
+		if !strings.Contains(buf.String(), "retry failed") || !strings.Contains(buf.String(), "attempts=3") {
+			t.Errorf("expected the failure to be logged, got %q", buf.String())
+		}
+	})
+
+	t.Run("never overlaps the next run", func(t *testing.T) {
+		job := &failingJob{failures: 5}
+		next := time.Now().Add(50 * time.Millisecond)
+		ctx := context.WithValue(context.Background(), nextActivationKey{}, next)
+		err := runJob(ctx, Retry(DiscardLogger, RetryPolicy{MaxAttempts: 5, Backoff: 20 * time.Millisecond})(ContextJob(job)))
+		if err == nil || job.runs != 2 {
+			t.Errorf("expected the job to give up after 2 runs, got %d, %v", job.runs, err)
+		}
+		if !job.deadlines[0].IsZero() || !job.deadlines[1].Equal(next) {
+			t.Errorf("expected the retry to be cancelled at the next run, got %v", job.deadlines)
+		}
+	})
+
+	t.Run("stops with the context", func(t *testing.T) {
+		job := &failingJob{failures: 5}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := runJob(ctx, Retry(DiscardLogger, RetryPolicy{MaxAttempts: 5, Backoff: time.Minute})(ContextJob(job)))
+		if err == nil || job.runs != 1 {
+			t.Errorf("expected a single run, got %d, %v", job.runs, err)
+		}
+	})
+
+	t.Run("clamps the jitter", func(t *testing.T) {
+		var buf bytes.Buffer
+		job := &failingJob{failures: 5}
+		retry := Retry(VerbosePrintfLogger(log.New(&buf, "", 0)), RetryPolicy{MaxAttempts: 6, Backoff: time.Millisecond, Jitter: 5})
+		if err := runJob(context.Background(), retry(ContextJob(job))); err != nil || job.runs != 6 {
+			t.Errorf("expected 6 runs and no error, got %d, %v", job.runs, err)
+		}
+		if strings.Contains(buf.String(), "delay=-") {
+			t.Errorf("expected no negative delay, got %q", buf.String())
+		}
+	})
+
+	t.Run("panics are not retried", func(t *testing.T) {
+		var runs int
+		job := NewChain(Retry(DiscardLogger, policy), Recover(DiscardLogger)).Then(FuncJobWithError(func() error {
+			runs++
+			panic("YOLO")
+		}))
+		if err := runJob(context.Background(), job); err == nil || runs != 1 {
+			t.Errorf("expected a single run, got %d, %v", runs, err)
+		}
+	})
+}
+
+func TestNextActivation(t *testing.T) {
+	if _, ok := NextActivation(context.Background()); ok {
+		t.Error("expected no next activation outside a run")
+	}
+
+	nexts := make(chan time.Time, 1)
+	cron := New(WithParser(secondParser), WithChain())
+	id, _ := cron.AddFuncWithContext("* * * * * ?", func(ctx context.Context) error {
+		next, _ := NextActivation(ctx)
+		nexts <- next
+		return nil
+	})
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected the job to run")
+	case next := <-nexts:
+		if expected := cron.Entry(id).Next; !next.Equal(expected) {
+			t.Errorf("expected %v, got %v", expected, next)
+		}
+	}
+}
+
+func TestFuncJobWithError(t *testing.T) {
+	errs := make(chan error, 1)
+	cron := New(WithParser(secondParser), WithChain(), WithLogger(DiscardLogger),
+		WithErrorHandler(func(_ EntryID, err error) { errs <- err }))
+	cron.AddJob("* * * * * ?", FuncJobWithError(func() error { return errors.New("YOLO") }))
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected the error to be reported")
+	case err := <-errs:
+		if err.Error() != "YOLO" {
+			t.Errorf("expected the job's error, got %v", err)
+		}
+	}
+}
@@ -14,6 +14,30 @@ type SpecSchedule struct {
 	Location *time.Location
 }
 
+// dayRules holds the Quartz-style day of month and day of week expressions,
+// which cannot be stored in the plain bit sets. A day matches its field if
+// it is in the bit set or matches one of these.
+type dayRules struct {
+	lastDom     uint64 // bit n: n days before the last day of the month ("L", "L-n")
+	lastWeekday bool   // the last Monday to Friday of the month ("LW")
+	weekdayDom  uint64 // bit d: the Monday to Friday nearest to day d ("dW")
+	lastDow     uint64 // bit w: the last weekday w of the month ("wL")
+	nthDow      uint64 // bit 8*w+n: the n-th weekday w of the month ("w#n")
+}
+
+// dayRuleSchedule is the Schedule returned by the parser for the specs using
+// the L, W or # expressions: the SpecSchedule holds the rest of the fields.
+// Specs without them still get a plain SpecSchedule.
+type dayRuleSchedule struct {
+	*SpecSchedule
+	rules dayRules
+}
+
+// Next returns the next time the schedule is activated, like SpecSchedule.Next.
+func (s dayRuleSchedule) Next(t time.Time) time.Time {
+	return s.next(t, s.rules)
+}
+
 // bounds provides a range of acceptable values (plus a map of name to value).
 type bounds struct {
 	min, max uint
@@ -59,6 +83,11 @@ const (
 // Next returns the next time this schedule is activated, greater than the given
 // time.  If no time can be found to satisfy the schedule, return the zero time.
 func (s *SpecSchedule) Next(t time.Time) time.Time {
+	return s.next(t, dayRules{})
+}
+
+// next is Next, the days also matching the given rules.
+func (s *SpecSchedule) next(t time.Time, rules dayRules) time.Time {
 	// General approach
 	//
 	// For Month, Day, Hour, Minute, Second:
@@ -117,7 +146,7 @@ WRAP:
 	// NOTE: This causes issues for daylight savings regimes where midnight does
 	// not exist.  For example: Sao Paulo has DST that transforms midnight on
 	// 11/3 into 1am. Handle that by noticing when the Hour ends up != 0.
-	for !dayMatches(s, t) {
+	for !dayMatches(s, rules, t) {
 		if !added {
 			added = true
 			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
@@ -179,13 +208,72 @@ WRAP:
 
 // dayMatches returns true if the schedule's day-of-week and day-of-month
 // restrictions are satisfied by the given time.
-func dayMatches(s *SpecSchedule, t time.Time) bool {
+func dayMatches(s *SpecSchedule, rules dayRules, t time.Time) bool {
 	var (
-		domMatch bool = 1<<uint(t.Day())&s.Dom > 0
-		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0
+		domMatch bool = 1<<uint(t.Day())&s.Dom > 0 || rules.domRuleMatches(t)
+		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0 || rules.dowRuleMatches(t)
 	)
 	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
 		return domMatch && dowMatch
 	}
 	return domMatch || dowMatch
 }
+
+// domRuleMatches returns true if the day of month of the given time matches
+// one of the L or W expressions.
+func (r dayRules) domRuleMatches(t time.Time) bool {
+	if r.lastDom == 0 && r.weekdayDom == 0 && !r.lastWeekday {
+		return false
+	}
+	day, last := t.Day(), daysIn(t)
+	if 1<<uint(last-day)&r.lastDom > 0 {
+		return true
+	}
+	if r.lastWeekday && day == nearestWeekday(t, last) {
+		return true
+	}
+	for d := 1; d <= last; d++ {
+		if 1<<uint(d)&r.weekdayDom > 0 && day == nearestWeekday(t, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// dowRuleMatches returns true if the day of week of the given time matches
+// one of the L or # expressions.
+func (r dayRules) dowRuleMatches(t time.Time) bool {
+	if r.lastDow == 0 && r.nthDow == 0 {
+		return false
+	}
+	weekday, day := uint(t.Weekday()), t.Day()
+	if 1<<weekday&r.lastDow > 0 && day+7 > daysIn(t) {
+		return true
+	}
+	nth := uint(day-1)/7 + 1
+	return 1<<(weekday*8+nth)&r.nthDow > 0
+}
+
+// daysIn returns the number of days in the month of the given time.
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the Monday to Friday closest to the given day of the
+// month of t, without leaving the month.
+func nearestWeekday(t time.Time, day int) int {
+	last := daysIn(t)
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
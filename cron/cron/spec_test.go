@@ -298,3 +298,61 @@ func TestSlash0NoHang(t *testing.T) {
 		t.Error("expected an error on 0 increment")
 	}
 }
+
+func TestNextExtended(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | LastDay | NearestWeekday | NthDayOfWeek)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Last day of the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * ?", "Tue Jul 31 00:00 2012"},
+		{"Tue Jul 31 00:00 2012", "0 0 0 L * ?", "Fri Aug 31 00:00 2012"},
+		{"Mon Jan 9 00:00 2012", "0 0 0 L Feb ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 L-2 * ?", "Sun Jul 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1,L * ?", "Tue Jul 31 00:00 2012"},
+		{"Tue Jul 31 23:35 2012", "0 0 0 1,L * ?", "Wed Aug 1 00:00 2012"},
+
+		// Last weekday of the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 LW * ?", "Tue Jul 31 00:00 2012"},
+		{"Wed Aug 1 00:00 2012", "0 0 0 LW Sep ?", "Fri Sep 28 00:00 2012"},
+		{"Wed Aug 1 00:00 2012", "0 0 0 lw Mar ?", "Fri Mar 29 00:00 2013"},
+
+		// Nearest weekday
+		{"Mon Jul 9 23:35 2012", "0 0 0 15W * ?", "Mon Jul 16 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 14W * ?", "Fri Jul 13 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1W Sep ?", "Mon Sep 3 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 30W Sep ?", "Fri Sep 28 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 31W Sep ?", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 31W Sep,Oct ?", "Wed Oct 31 00:00 2012"},
+
+		// Last given weekday of the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5L", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * FRIL", "Fri Jul 27 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * L", "Sat Jul 28 00:00 2012"},
+
+		// Nth given weekday of the month
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5#3", "Fri Jul 20 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * MON#1", "Mon Aug 6 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 1#5", "Mon Jul 30 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 2#5", "Tue Jul 31 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? * 3#5", "Wed Aug 29 00:00 2012"},
+
+		// Restricted day of month and day of week: either may match
+		{"Mon Jul 9 23:35 2012", "0 0 0 L * 5#3", "Fri Jul 20 00:00 2012"},
+		{"Fri Jul 20 00:00 2012", "0 0 0 L * 5#3", "Tue Jul 31 00:00 2012"},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package cron
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MisfirePolicy decides how an entry catches up on the runs it missed while
+// the Cron was not running.
+type MisfirePolicy int
+
+const (
+	// MisfireSkip drops the missed runs. The entry simply waits for its next
+	// activation time, which is what a Cron without a JobStore does.
+	MisfireSkip MisfirePolicy = iota
+
+	// MisfireRunOnce runs the job a single time on start, no matter how many
+	// activations were missed.
+	MisfireRunOnce
+
+	// MisfireRunAll runs the job once for every missed activation, up to
+	// maxMissedRuns.
+	MisfireRunAll
+)
+
+// maxMissedRuns bounds the number of catch-up runs started for one entry by
+// MisfireRunAll, e.g. for an "@every 1s" entry after a week of downtime.
+const maxMissedRuns = 1000
+
+// StoredEntry is the persisted state of an Entry.
+type StoredEntry struct {
+	ID   EntryID   `json:"id"`
+	Name string    `json:"name,omitempty"`
+	Spec string    `json:"spec,omitempty"`
+	Prev time.Time `json:"prev"`
+	Next time.Time `json:"next"`
+
+	// Paused entries stay paused after a restart.
+	Paused bool `json:"paused,omitempty"`
+
+	// Runs counts the activations which were run, so that an entry whose
+	// Schedule is limited with Times does not start over after a restart.
+	Runs int `json:"runs,omitempty"`
+}
+
+// key identifies the stored entry the same way Entry.key does.
+func (s StoredEntry) key() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return strconv.Itoa(int(s.ID))
+}
+
+// JobStore persists the entries of a Cron, so that their Prev and Next times
+// survive a process restart.
+//
+// Entries are matched with their saved state by Name, or by ID for entries
+// without a name. IDs are assigned in the order entries are added, so unnamed
+// entries are only matched correctly if they are registered in the same order
+// on every start.
+type JobStore interface {
+	// Load returns the entries passed to the last Save, or none if nothing
+	// has been saved yet.
+	Load() ([]StoredEntry, error)
+
+	// Save replaces the stored entries with the given ones.
+	Save(entries []StoredEntry) error
+}
+
+// FileJobStore is a JobStore that keeps the entries as JSON in a single file.
+// The file is replaced atomically on every save.
+type FileJobStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileJobStore returns a JobStore backed by the file at path. The file is
+// created on the first save.
+func NewFileJobStore(path string) *FileJobStore {
+	return &FileJobStore{path: path}
+}
+
+// Load reads the entries from the file.
+func (s *FileJobStore) Load() ([]StoredEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []StoredEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Save writes the entries to a temporary file and renames it over the old one,
+// so a crash never leaves a half-written store behind.
+func (s *FileJobStore) Save(entries []StoredEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// loadEntries returns the stored entries by key, or nil if there is no store
+// or it could not be read.
+func (c *Cron) loadEntries() map[string]StoredEntry {
+	if c.store == nil {
+		return nil
+	}
+	entries, err := c.store.Load()
+	if err != nil {
+		c.logger.Error(err, "load entries")
+		return nil
+	}
+	stored := make(map[string]StoredEntry, len(entries))
+	for _, s := range entries {
+		stored[s.key()] = s
+	}
+	return stored
+}
+
+// saveEntries writes the current entries to the store, if there is one.
+func (c *Cron) saveEntries() {
+	if c.store == nil {
+		return
+	}
+	// 结束了的 entry 也要保存，重启之后它的 runs 才不会从 0 开始
+	var entries = make([]StoredEntry, 0, len(c.entries)+len(c.done))
+	for _, e := range append(c.entries[:len(c.entries):len(c.entries)], c.done...) {
+		entries = append(entries, StoredEntry{
+			ID:     e.ID,
+			Name:   e.Name,
+			Spec:   e.Spec,
+			Prev:   e.Prev,
+			Next:   e.Next,
+			Paused: e.Paused,
+			Runs:   e.runs,
+		})
+	}
+	if err := c.store.Save(entries); err != nil {
+		c.logger.Error(err, "save entries")
+	}
+}
+
+// catchUp restores the entry's Prev and run count from its stored state and
+// handles the activations it missed up to now according to its MisfirePolicy.
+// The runs caught up on count against the limit set with Times: the entry
+// only catches up on the runs it has left.
+func (c *Cron) catchUp(e *Entry, s StoredEntry, now time.Time) {
+	e.Prev = s.Prev
+	e.runs = s.Runs
+	if e.Paused || s.Next.IsZero() || s.Next.After(now) || len(e.DependsOn()) > 0 {
+		return
+	}
+
+	limit := maxMissedRuns
+	if _, n := runLimit(e.Schedule); n >= 0 && n-e.runs < limit {
+		limit = n - e.runs
+	}
+	var missed []time.Time
+	for t := s.Next; !t.IsZero() && !t.After(now) && len(missed) < limit; t = e.Schedule.Next(t) {
+		missed = append(missed, t)
+	}
+	if len(missed) == 0 {
+		return // 停机之前就已经跑完了 Times 限制的次数
+	}
+	last := missed[len(missed)-1]
+	e.Next = e.Schedule.Next(now) // 补跑的 job 也能通过 NextActivation 知道下一次触发的时间
+
+	switch e.MisfirePolicy {
+	case MisfireRunOnce:
+		c.startJob(e, last)
+		e.Prev = last
+		e.runs++
+	case MisfireRunAll:
+		// 最多补跑到 Times 限制的次数，多出来的 activation 丢掉
+		for _, t := range missed {
+			c.startJob(e, t)
+		}
+		e.Prev = last
+		e.runs += len(missed)
+	default:
+		c.logger.Info("skip missed", "entry", e.ID, "missed", len(missed))
+		return
+	}
+	c.logger.Info("catch up", "now", now, "entry", e.ID, "missed", len(missed))
+}
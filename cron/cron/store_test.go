@@ -0,0 +1,182 @@
+package cron
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryStore is a JobStore that keeps the entries in memory.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries []StoredEntry
+}
+
+func (s *memoryStore) Load() ([]StoredEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StoredEntry(nil), s.entries...), nil
+}
+
+func (s *memoryStore) Save(entries []StoredEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append([]StoredEntry(nil), entries...)
+	return nil
+}
+
+func (s *memoryStore) Saved() []StoredEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StoredEntry(nil), s.entries...)
+}
+
+func TestFileJobStore(t *testing.T) {
+	store := NewFileJobStore(filepath.Join(t.TempDir(), "entries.json"))
+
+	entries, err := store.Load()
+	if err != nil || entries != nil {
+		t.Fatalf("expected no entries before the first save, got %v, %v", entries, err)
+	}
+
+	var (
+		prev = time.Date(2022, 12, 10, 8, 0, 0, 0, time.UTC)
+		next = time.Date(2022, 12, 10, 9, 0, 0, 0, time.UTC)
+	)
+	saved := []StoredEntry{
+		{ID: 1, Name: "backup", Spec: "@hourly", Prev: prev, Next: next},
+		{ID: 2, Spec: "*/5 * * * *"},
+	}
+	if err := store.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entries, saved) {
+		t.Errorf("expected %v, got %v", saved, entries)
+	}
+}
+
+func TestMisfirePolicy(t *testing.T) {
+	tests := []struct {
+		policy MisfirePolicy
+		runs   int64
+	}{
+		{MisfireSkip, 0},
+		{MisfireRunOnce, 1},
+		{MisfireRunAll, 4},
+	}
+
+	for _, test := range tests {
+		now := time.Now().Truncate(time.Second)
+		store := &memoryStore{entries: []StoredEntry{{
+			Name: "hourly",
+			Prev: now.Add(-4*time.Hour - 30*time.Minute),
+			Next: now.Add(-3*time.Hour - 30*time.Minute),
+		}}}
+
+		var runs int64
+		cron := New(WithJobStore(store), WithChain())
+		cron.Schedule(Every(time.Hour), FuncJob(func() { atomic.AddInt64(&runs, 1) }),
+			WithName("hourly"), WithMisfirePolicy(test.policy))
+		cron.Start()
+		time.Sleep(50 * time.Millisecond)
+		<-cron.Stop().Done()
+
+		if actual := atomic.LoadInt64(&runs); actual != test.runs {
+			t.Errorf("policy %d: expected %d runs, got %d", test.policy, test.runs, actual)
+		}
+		saved := store.Saved()
+		if len(saved) != 1 || saved[0].Name != "hourly" {
+			t.Fatalf("policy %d: expected the entry to be saved, got %v", test.policy, saved)
+		}
+		if !saved[0].Next.After(now) {
+			t.Errorf("policy %d: expected a future Next, got %v", test.policy, saved[0].Next)
+		}
+		expectedPrev := now.Add(-4*time.Hour - 30*time.Minute)
+		if test.policy != MisfireSkip {
+			expectedPrev = now.Add(-30 * time.Minute)
+		}
+		if !saved[0].Prev.Equal(expectedPrev) {
+			t.Errorf("policy %d: expected Prev %v, got %v", test.policy, expectedPrev, saved[0].Prev)
+		}
+	}
+}
+
+// Unnamed entries are matched with their stored state by ID.
+func TestStoreMatchesByID(t *testing.T) {
+	prev := time.Now().Add(-time.Minute).Truncate(time.Second)
+	store := &memoryStore{entries: []StoredEntry{{ID: 2, Prev: prev}}}
+
+	cron := New(WithJobStore(store))
+	cron.Schedule(Every(time.Hour), FuncJob(func() {}))
+	id := cron.Schedule(Every(time.Hour), FuncJob(func() {}))
+	cron.Start()
+	defer cron.Stop()
+
+	if actual := cron.Entry(id).Prev; !actual.Equal(prev) {
+		t.Errorf("expected Prev %v, got %v", prev, actual)
+	}
+	if actual := cron.Entry(1).Prev; !actual.IsZero() {
+		t.Errorf("expected zero Prev for the first entry, got %v", actual)
+	}
+}
+
+// Paused entries stay paused after a restart.
+func TestStorePaused(t *testing.T) {
+	store := &memoryStore{}
+
+	cron := New(WithJobStore(store))
+	id := cron.Schedule(Every(time.Hour), FuncJob(func() {}), WithName("paused"))
+	cron.Start()
+	cron.Pause(id)
+	cron.Stop()
+	if saved := store.Saved(); len(saved) != 1 || !saved[0].Paused {
+		t.Fatalf("expected a paused entry to be saved, got %v", saved)
+	}
+
+	cron = New(WithJobStore(store))
+	id = cron.Schedule(Every(time.Hour), FuncJob(func() {}), WithName("paused"))
+	cron.Start()
+	defer cron.Stop()
+	if entry := cron.Entry(id); !entry.Paused || !entry.Next.IsZero() {
+		t.Errorf("expected the entry to be paused, got %+v", entry)
+	}
+}
+
+// Catching up counts against the limit set with Times, across restarts.
+func TestMisfireRunAllTimes(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	store := &memoryStore{entries: []StoredEntry{{
+		Name: "thrice",
+		Prev: now.Add(-10*time.Hour - 30*time.Minute),
+		Next: now.Add(-9*time.Hour - 30*time.Minute),
+		Runs: 1,
+	}}}
+
+	for restart, expected := range []int64{2, 0} {
+		var runs int64
+		cron := New(WithJobStore(store), WithChain())
+		id := cron.Schedule(Times(Every(time.Hour), 3), FuncJob(func() { atomic.AddInt64(&runs, 1) }),
+			WithName("thrice"), WithMisfirePolicy(MisfireRunAll))
+		cron.Start()
+		time.Sleep(50 * time.Millisecond)
+		entry := cron.Entry(id)
+		<-cron.Stop().Done()
+
+		if actual := atomic.LoadInt64(&runs); actual != expected {
+			t.Errorf("restart %d: expected %d runs, got %d", restart, expected, actual)
+		}
+		if entry.Finished.IsZero() {
+			t.Errorf("restart %d: expected the entry to be finished, got %+v", restart, entry)
+		}
+		if saved := store.Saved(); len(saved) != 1 || saved[0].Runs != 3 {
+			t.Errorf("restart %d: expected 3 runs to be saved, got %v", restart, saved)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// constraint restricts the values a named parameter matches, see
+// newConstraint.
+type constraint struct {
+	key   string // name of the parameter
+	expr  string // as written between '<' and '>'
+	match func(string) bool
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// constraintTypes are the predefined constraints.
+var constraintTypes = map[string]func(string) bool{
+	"int": func(s string) bool {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	},
+	"uint": func(s string) bool {
+		_, err := strconv.ParseUint(s, 10, 64)
+		return err == nil
+	},
+	"uuid": uuidPattern.MatchString,
+}
+
+// paramName returns the name of the wildcard, without its ':' or '*' and its
+// constraint.
+func paramName(wildcard string) string {
+	if i := strings.IndexByte(wildcard, '<'); i > 0 {
+		return wildcard[1:i]
+	}
+	return wildcard[1:]
+}
+
+// newConstraint returns the constraint of a wildcard such as ":id<int>", or
+// nil if it has none. The constraint is either one of the predefined types
+// int, uint and uuid, or a regular expression which has to match the whole
+// segment.
+func newConstraint(wildcard, fullPath string) *constraint {
+	i := strings.IndexByte(wildcard, '<')
+	if i < 0 {
+		return nil
+	}
+	if wildcard[len(wildcard)-1] != '>' || i == len(wildcard)-2 {
+		panic("constraint must be enclosed in '<' and '>' in path '" + fullPath + "'")
+	}
+	c := &constraint{key: wildcard[1:i], expr: wildcard[i+1 : len(wildcard)-1]}
+	if match, ok := constraintTypes[c.expr]; ok {
+		c.match = match
+		return c
+	}
+	re, err := regexp.Compile("^(?:" + c.expr + ")$")
+	if err != nil {
+		panic("invalid constraint '" + c.expr + "' in path '" + fullPath + "': " + err.Error())
+	}
+	c.match = re.MatchString
+	return c
+}
+
+// paramKey returns the key of the param node's values.
+func (n *node) paramKey() string {
+	if n.constraint != nil {
+		return n.constraint.key
+	}
+	return n.path[1:]
+}
+
+// paramChild returns the first param child of the node matching the segment,
+// starting from the i-th one, and its index: the constrained ones come first,
+// then the one without constraint. It returns nil if no param child matches.
+func (n *node) paramChild(segment string, i int) (*node, int) {
+	for ; i < len(n.children); i++ {
+		child := n.children[i]
+		if child.constraint == nil || child.constraint.match(segment) {
+			return child, i
+		}
+	}
+	return nil, i
+}
+
+// hasParamChild reports whether a param child after the i-th one matches the
+// segment as well, in which case the rest of the path may only match below it.
+func (n *node) hasParamChild(segment string, i int) bool {
+	child, _ := n.paramChild(segment, i+1)
+	return child != nil
+}
+
+// matchParam matches the path below the param node n, whose value is
+// path[:end], saving the value like getValue. It is used to try the param
+// children sharing a position one after the other.
+func (n *node) matchParam(path string, end int, params func() *Params, ps *Params) (Handle, *Params) {
+	if params != nil {
+		if ps == nil {
+			ps = params()
+		}
+		i := len(*ps)
+		*ps = (*ps)[:i+1]
+		(*ps)[i] = Param{
+			Key:   n.paramKey(),
+			Value: path[:end],
+		}
+	}
+	if end == len(path) {
+		return n.handle, ps
+	}
+	if len(n.children) == 0 {
+		return nil, ps
+	}
+	handle, ps, _ := n.children[0].getValueFrom(path[end:], params, ps)
+	return handle, ps
+}
+
+// addParamChild adds a param child for the path, which starts with a named
+// parameter, next to the existing param children of the node. Params may share
+// a position as long as at most one of them has no constraint; the ones with a
+// constraint are tried first, in the order they were added.
+// It returns false if the parameter conflicts with the existing children.
+func (n *node) addParamChild(path, fullPath string, handle Handle) bool {
+	wildcard, i, valid := findWildcard(path)
+	if i != 0 || !valid || wildcard[0] != ':' {
+		return false
+	}
+	constrained := strings.IndexByte(wildcard, '<') > 0
+	for _, child := range n.children {
+		if child.nType != param || child.constraint == nil && !constrained {
+			return false
+		}
+	}
+
+	wild := &node{}
+	wild.insertChild(path, fullPath, handle)
+	child := wild.children[0]
+
+	last := len(n.children) - 1
+	if constrained && n.children[last].constraint == nil {
+		n.children = append(n.children[:last], child, n.children[last])
+	} else {
+		n.children = append(n.children, child)
+	}
+	return true
+}
+
+// Int returns the value of the first Param which key matches the given name,
+// as an int. The error is the one of strconv.Atoi, e.g. if there is no such
+// Param.
+func (ps Params) Int(name string) (int, error) {
+	return strconv.Atoi(ps.ByName(name))
+}
+
+// Uint returns the value of the first Param which key matches the given name,
+// as an uint64. The error is the one of strconv.ParseUint.
+func (ps Params) Uint(name string) (uint64, error) {
+	return strconv.ParseUint(ps.ByName(name), 10, 64)
+}
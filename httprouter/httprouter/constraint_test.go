@@ -0,0 +1,165 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTreeConstraints(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/users/:id<int>",
+		"/users/:id<int>/posts",
+		"/users/:slug<[a-z-]+>",
+		"/users/:name",
+		"/users/:name/posts",
+		"/keys/:key<uuid>",
+		"/pages/:n<uint>",
+		"/pages/:n<[a-z]*>",
+		"/u/:id<int>/posts",
+		"/u/:name/profile",
+	}
+	for _, route := range routes {
+		recv := catchPanic(func() {
+			tree.addRoute(route, fakeHandler(route))
+		})
+		if recv != nil {
+			t.Fatalf("panic inserting route '%s': %v", route, recv)
+		}
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/users/42", false, "/users/:id<int>", Params{Param{"id", "42"}}},
+		{"/users/-42", false, "/users/:id<int>", Params{Param{"id", "-42"}}},
+		{"/users/42/posts", false, "/users/:id<int>/posts", Params{Param{"id", "42"}}},
+		{"/users/jose-maria", false, "/users/:slug<[a-z-]+>", Params{Param{"slug", "jose-maria"}}},
+		{"/users/Jose", false, "/users/:name", Params{Param{"name", "Jose"}}},
+		{"/users/Jose/posts", false, "/users/:name/posts", Params{Param{"name", "Jose"}}},
+		{"/users/jose-maria/posts", false, "/users/:name/posts", Params{Param{"name", "jose-maria"}}}, // backtracking
+		{"/keys/6ba7b810-9dad-11d1-80b4-00c04fd430c8", false, "/keys/:key<uuid>", Params{Param{"key", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}}},
+		{"/keys/42", true, "", nil},
+		{"/pages/7", false, "/pages/:n<uint>", Params{Param{"n", "7"}}},
+		{"/pages/-7", true, "", nil},
+		{"/pages/abc", false, "/pages/:n<[a-z]*>", Params{Param{"n", "abc"}}},
+		{"/u/42/posts", false, "/u/:id<int>/posts", Params{Param{"id", "42"}}},
+		{"/u/42/profile", false, "/u/:name/profile", Params{Param{"name", "42"}}},
+		{"/u/42/other", true, "", Params{Param{"name", "42"}}},
+	})
+
+	for path, expected := range map[string]string{
+		"/U/42/PROFILE":     "/u/42/profile",
+		"/USERS/jose/POSTS": "/users/jose/posts",
+		"/u/42/profile/":    "/u/42/profile",
+		"/pages/ABC":        "",
+	} {
+		out, found := tree.findCaseInsensitivePath(path, true)
+		if string(out) != expected || found != (expected != "") {
+			t.Errorf("%s: expected %q, got %q (%v)", path, expected, out, found)
+		}
+	}
+
+	checkPriorities(t, tree)
+}
+
+func TestTreeConstraintConflicts(t *testing.T) {
+	routes := []testRoute{
+		{"/users/:id<int>", false},
+		{"/users/:num<int>", false},
+		{"/users/:name", false},
+		{"/users/:other", true},
+		{"/users/:slug<[a-z]+>", false},
+		{"/users/new", true},
+		{"/files/*path", false},
+		{"/files/:name<int>", true},
+		{"/ids/:id<[a-z]*>", false},
+	}
+	testRoutes(t, routes)
+}
+
+func TestTreeInvalidConstraint(t *testing.T) {
+	routes := [...]string{
+		"/src/*path<int>",
+		"/ids/:<int>",
+		"/ids/:id<int",
+		"/ids/:id<>",
+		"/ids/:id<[a-z>",
+	}
+	for _, route := range routes {
+		tree := &node{}
+		recv := catchPanic(func() {
+			tree.addRoute(route, nil)
+		})
+		if recv == nil {
+			t.Errorf("no panic for invalid route '%s'", route)
+		}
+	}
+}
+
+func TestRouterConstraints(t *testing.T) {
+	var id int
+	var idErr error
+	var name string
+	router := New()
+	router.GET("/users/:id<int>", func(_ http.ResponseWriter, _ *http.Request, ps Params) {
+		id, idErr = ps.Int("id")
+	}, WithName("user"))
+	router.GET("/users/:id<int>/:name<[a-z]+>", func(_ http.ResponseWriter, _ *http.Request, ps Params) {
+		name = ps.ByName("name")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if w.Code != http.StatusOK || idErr != nil || id != 42 {
+		t.Errorf("expected id 42, got %d (%v), status %d", id, idErr, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/gopher", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unsatisfied constraint, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42/gopher", nil))
+	if w.Code != http.StatusOK || name != "gopher" {
+		t.Errorf("expected name gopher, got %q, status %d", name, w.Code)
+	}
+
+	if url, err := router.URL("user", "id", "7"); err != nil || url != "/users/7" {
+		t.Errorf("expected /users/7, got %s (%v)", url, err)
+	}
+	if url, err := router.URL("user", "id", "seven"); err == nil {
+		t.Errorf("expected an error for an unsatisfied constraint, got %s", url)
+	}
+
+	var routes []string
+	for _, rt := range router.Routes() {
+		routes = append(routes, rt.Path)
+	}
+	if expected := []string{"/users/:id<int>", "/users/:id<int>/:name<[a-z]+>"}; !reflect.DeepEqual(routes, expected) {
+		t.Errorf("expected routes %v, got %v", expected, routes)
+	}
+}
+
+func TestParamsTyped(t *testing.T) {
+	ps := Params{Param{"id", "-3"}, Param{"page", "12"}}
+	if id, err := ps.Int("id"); err != nil || id != -3 {
+		t.Errorf("expected id -3, got %d (%v)", id, err)
+	}
+	if page, err := ps.Uint("page"); err != nil || page != 12 {
+		t.Errorf("expected page 12, got %d (%v)", page, err)
+	}
+	if _, err := ps.Uint("id"); err == nil {
+		t.Error("expected an error for a negative uint")
+	}
+	if _, err := ps.Int("missing"); err == nil {
+		t.Error("expected an error for a missing param")
+	}
+}
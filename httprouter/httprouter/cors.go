@@ -0,0 +1,143 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS is a Cross-Origin Resource Sharing policy, see Router.CORS.
+//
+// Preflight requests are answered by the automatic OPTIONS replies of the
+// router, see Router.HandleOPTIONS. Access-Control-Allow-Methods then lists the
+// methods allowed for the path, like the Allow header. The responses of the
+// matched handles get the other headers of the policy, set before the handle is
+// called.
+//
+// Requests from origins not allowed get no CORS header at all, so browsers
+// reject the responses.
+type CORS struct {
+	// Origins allowed to make requests, such as "https://example.com". An
+	// origin may contain a single '*' matching one or more letters, digits,
+	// '-' or '.', e.g. "https://*.example.com" for the subdomains. "*" allows
+	// any origin, unless AllowCredentials is set.
+	AllowedOrigins []string
+
+	// Request headers allowed in actual requests. If empty, the headers asked
+	// for by preflight requests are allowed.
+	AllowedHeaders []string
+
+	// Response headers exposed to the scripts of the origin.
+	ExposedHeaders []string
+
+	// Whether the requests may include credentials, such as cookies.
+	// The allowed origin is then always given explicitly, never as "*", and
+	// the origins must be listed in AllowedOrigins: "*" allows none, so that
+	// not every website can make credentialed requests.
+	AllowCredentials bool
+
+	// How long the results of a preflight request may be cached, in seconds
+	// precision. Zero leaves it to the browser.
+	MaxAge time.Duration
+}
+
+// allowOrigin reports whether the origin matches one of the allowed origins.
+func (c *CORS) allowOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			// 带 credentials 的请求不能对所有网站开放
+			if c.AllowCredentials {
+				continue
+			}
+			return true
+		}
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+		// https://*.example.com
+		if i := strings.IndexByte(allowed, '*'); i >= 0 {
+			prefix, suffix := allowed[:i], allowed[i+1:]
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.EqualFold(origin[:len(prefix)], prefix) &&
+				strings.EqualFold(origin[len(origin)-len(suffix):], suffix) &&
+				isHostLabels(origin[len(prefix):len(origin)-len(suffix)]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isHostLabels reports whether s only contains the characters of host names,
+// so that the '*' of an allowed origin cannot match e.g. "evil.com/".
+func isHostLabels(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// anyOrigin reports whether any origin is allowed.
+func (c *CORS) anyOrigin() bool {
+	if c.AllowCredentials {
+		return false
+	}
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// decorate sets the headers of a response to the origin. It reports whether
+// the origin is allowed.
+func (c *CORS) decorate(h http.Header, origin string) bool {
+	if origin == "" || !c.allowOrigin(origin) {
+		return false
+	}
+
+	if c.anyOrigin() {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		// 回复的内容跟着 Origin 变，缓存也要按 Origin 区分
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Add("Vary", "Origin")
+	}
+	if c.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(c.ExposedHeaders) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+	}
+	return true
+}
+
+// preflight sets the headers of the reply to a preflight request, the methods
+// allowed for the path being given by allow.
+func (c *CORS) preflight(h http.Header, req *http.Request, allow string) {
+	if req.Header.Get("Access-Control-Request-Method") == "" ||
+		!c.decorate(h, req.Header.Get("Origin")) {
+		return
+	}
+	h.Del("Access-Control-Expose-Headers") // only for actual responses
+
+	h.Set("Access-Control-Allow-Methods", allow)
+	if len(c.AllowedHeaders) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+	} else if headers := req.Header.Get("Access-Control-Request-Headers"); headers != "" {
+		h.Set("Access-Control-Allow-Headers", headers)
+		h.Add("Vary", "Access-Control-Request-Headers")
+	}
+	if c.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+	}
+}
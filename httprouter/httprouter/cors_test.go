@@ -0,0 +1,156 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSAllowOrigin(t *testing.T) {
+	c := &CORS{AllowedOrigins: []string{"https://example.com", "https://*.example.org"}}
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://example.com", true},
+		{"https://EXAMPLE.com", true},
+		{"http://example.com", false},
+		{"https://api.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://.example.org", false},
+		{"https://example.org", false},
+		{"https://evil.com", false},
+		{"https://evil.com/.example.org", false},
+		{"https://evil.com:.example.org", false},
+		{"https://evil.com?.example.org", false},
+		{"https://a.b-c.example.org", true},
+	}
+	for _, test := range tests {
+		if allowed := c.allowOrigin(test.origin); allowed != test.allowed {
+			t.Errorf("%s: expected allowed %v, got %v", test.origin, test.allowed, allowed)
+		}
+	}
+
+	if !(&CORS{AllowedOrigins: []string{"*"}}).allowOrigin("https://any.com") {
+		t.Error("expected any origin to be allowed")
+	}
+
+	// Credentials require the origins to be listed
+	c = &CORS{AllowedOrigins: []string{"*", "https://example.com"}, AllowCredentials: true}
+	if c.allowOrigin("https://any.com") || c.anyOrigin() {
+		t.Error("expected any origin to be refused with credentials")
+	}
+	if !c.allowOrigin("https://example.com") {
+		t.Error("expected the listed origin to be allowed with credentials")
+	}
+}
+
+func TestRouterCORSPreflight(t *testing.T) {
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router := New()
+	router.GET("/users/:id", handle)
+	router.DELETE("/users/:id", handle)
+	router.CORS = &CORS{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		ExposedHeaders:   []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	expected := map[string]string{
+		"Allow":                            "DELETE, GET, OPTIONS",
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "DELETE, GET, OPTIONS",
+		"Access-Control-Allow-Headers":     "Content-Type",
+		"Access-Control-Max-Age":           "600",
+		"Access-Control-Expose-Headers":    "",
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	for key, value := range expected {
+		if got := w.Header().Get(key); got != value {
+			t.Errorf("%s: expected %q, got %q", key, value, got)
+		}
+	}
+	if vary := w.Header().Values("Vary"); len(vary) != 2 {
+		t.Errorf("expected Vary on the origin and the requested headers, got %v", vary)
+	}
+
+	// A forbidden origin only gets the Allow header
+	req.Header.Set("Origin", "https://evil.com")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Header().Get("Allow") == "" || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected no CORS header for a forbidden origin, got %v", w.Header())
+	}
+
+	// Unknown paths are not answered
+	req = httptest.NewRequest(http.MethodOptions, "/posts/1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected status %d without CORS header, got %d %v", http.StatusNotFound, w.Code, w.Header())
+	}
+}
+
+func TestRouterCORSActual(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(w http.ResponseWriter, _ *http.Request, _ Params) {
+		w.Header().Set("X-Total", "1")
+	})
+	router.CORS = &CORS{
+		AllowedOrigins: []string{"*"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		ExposedHeaders: []string{"X-Total"},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Origin", "https://any.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+		t.Errorf("expected any origin, got %q", origin)
+	}
+	if exposed := w.Header().Get("Access-Control-Expose-Headers"); exposed != "X-Total" {
+		t.Errorf("expected the exposed headers, got %q", exposed)
+	}
+	if w.Header().Get("Vary") != "" || w.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Errorf("expected no preflight header, got %v", w.Header())
+	}
+
+	// Same-origin requests are left alone
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+		t.Errorf("expected no CORS header without origin, got %q", origin)
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	req.Header.Set("Origin", "https://any.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	req.Header.Set("Access-Control-Request-Headers", "X-Custom")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if headers := w.Header().Get("Access-Control-Allow-Headers"); headers != "Content-Type, Authorization" {
+		t.Errorf("expected the allowed headers, got %q", headers)
+	}
+	if maxAge := w.Header().Get("Access-Control-Max-Age"); maxAge != "" {
+		t.Errorf("expected no max age, got %q", maxAge)
+	}
+}
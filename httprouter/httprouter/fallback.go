@@ -0,0 +1,262 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WithFallback registers the route in the fallback matcher instead of the
+// radix tree, e.g. because it would conflict with the routes of the tree:
+//
+//	router.GET("/vendors", Vendors)
+//	router.GET("/v:version/users", Users, httprouter.WithFallback())
+//
+// Routes the tree cannot hold always go to the fallback matcher: those with
+// several wildcards in a path segment, such as "/:year-:month", or with a
+// catch-all before the end, such as "/files/*path/meta". The fallback matcher
+// is only consulted if the tree has no route for the request.
+//
+// In fallback routes, wildcard names are made of letters, digits and '_', so
+// that static text may follow them in the segment. A named parameter matches a
+// non-empty part of a segment, or what its constraint matches, which is a
+// regular expression or a predefined type as in the tree. A catch-all matches
+// one or more characters, '/' included. The routes of a method are tried in
+// the order they were registered, the first match wins.
+//
+// Registering a fallback route panics if one of the same method has the same
+// shape: the same static text, with wildcards of the same kind in the same
+// places, whatever their names and constraints, e.g. "/v:version<[0-9]+>/users"
+// and "/v:version/users". Routes which overlap otherwise, such as "/:name.:ext"
+// and "/:year-:month", are not detected: the first registered one wins.
+func WithFallback() RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.fallback = true
+	}
+}
+
+// pattern is a route of the fallback matcher.
+type pattern struct {
+	path      string // as registered
+	shape     string // the path without the names and constraints of wildcards
+	parts     []patternPart
+	re        *regexp.Regexp
+	wildcards []int // index in parts of each wildcard
+	groups    []int // index of the submatch of each wildcard
+	rt        *route
+}
+
+// patternPart is the static text or the wildcard of a pattern.
+type patternPart struct {
+	static   string
+	key      string // name of the wildcard, empty for static text
+	catchAll bool
+	expr     string            // constraint, as written between '<' and '>'
+	re       *regexp.Regexp    // matches a whole value of the wildcard
+	check    func(string) bool // predefined constraint, checked after matching
+}
+
+// constraintExprs are the regular expressions of the predefined constraints,
+// see constraintTypes.
+var constraintExprs = map[string]string{
+	"int":  `[-+]?[0-9]+`,
+	"uint": `\+?[0-9]+`,
+	"uuid": `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// fitsTree reports whether the radix tree can hold the path: at most one
+// wildcard per segment, nothing after the constraint of a param in its segment,
+// and a catch-all only at the end.
+func fitsTree(path string) bool {
+	for rest := path; ; {
+		wildcard, i, valid := findWildcard(rest)
+		if i < 0 {
+			return true
+		}
+		if !valid || wildcard[0] == '*' && i+len(wildcard) != len(rest) {
+			return false
+		}
+		if strings.IndexByte(wildcard, '<') > 0 && wildcard[len(wildcard)-1] != '>' {
+			return false
+		}
+		rest = rest[i+len(wildcard):]
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// compilePattern parses the path of a fallback route.
+func compilePattern(path string) *pattern {
+	p := &pattern{path: path}
+	var expr, shape strings.Builder
+	expr.WriteByte('^')
+	for i := 0; i < len(path); {
+		c := path[i]
+		if c != ':' && c != '*' {
+			end := strings.IndexAny(path[i:], ":*")
+			if end < 0 {
+				end = len(path) - i
+			}
+			p.parts = append(p.parts, patternPart{static: path[i : i+end]})
+			expr.WriteString(regexp.QuoteMeta(path[i : i+end]))
+			shape.WriteString(path[i : i+end])
+			i += end
+			continue
+		}
+
+		end := i + 1
+		for end < len(path) && isNameChar(path[end]) {
+			end++
+		}
+		part := patternPart{key: path[i+1 : end], catchAll: c == '*'}
+		shape.WriteByte(c)
+		if part.key == "" {
+			panic("wildcards must be named with a non-empty name in path '" + path + "'")
+		}
+
+		wildcard := `[^/]+`
+		if part.catchAll {
+			wildcard = `.+`
+		} else if end < len(path) && path[end] == '<' {
+			closing := strings.IndexByte(path[end:], '>')
+			if closing < 2 {
+				panic("constraint must be enclosed in '<' and '>' in path '" + path + "'")
+			}
+			wildcard = path[end+1 : end+closing]
+			part.expr = wildcard
+			if typed, ok := constraintExprs[wildcard]; ok {
+				part.check = constraintTypes[wildcard]
+				wildcard = typed
+			} else if _, err := regexp.Compile(wildcard); err != nil {
+				panic("invalid constraint '" + wildcard + "' in path '" + path + "': " + err.Error())
+			}
+			end += closing + 1
+		}
+		part.re = regexp.MustCompile("^(?:" + wildcard + ")$")
+
+		// 用编号命名 group，constraint 自己的 group 不会影响取值
+		fmt.Fprintf(&expr, "(?P<p%d>%s)", len(p.wildcards), wildcard)
+		p.wildcards = append(p.wildcards, len(p.parts))
+		p.parts = append(p.parts, part)
+		i = end
+	}
+	expr.WriteByte('$')
+	p.re = regexp.MustCompile(expr.String())
+	p.shape = shape.String()
+
+	for i := range p.wildcards {
+		p.groups = append(p.groups, p.re.SubexpIndex("p"+strconv.Itoa(i)))
+	}
+	return p
+}
+
+// addFallback adds the route to the fallback matcher of its method. It panics
+// if a route with the same shape is already registered, see WithFallback.
+func (t *routeTable) addFallback(rt *route) {
+	p := compilePattern(rt.path)
+	p.rt = rt
+	for _, existing := range t.fallback[rt.method] {
+		if existing.path == rt.path {
+			panic("a handle is already registered for path '" + rt.path + "'")
+		}
+		if existing.shape == p.shape {
+			panic("new path '" + rt.path +
+				"' conflicts with existing fallback path '" + existing.path + "'")
+		}
+	}
+
+	if t.fallback == nil {
+		t.fallback = make(map[string][]*pattern)
+	}
+	t.fallback[rt.method] = append(t.fallback[rt.method], p)
+	if t.patterns == nil {
+		t.patterns = make(map[string]*pattern)
+	}
+	t.patterns[rt.path] = p
+}
+
+// matchFallback returns the handle of the first fallback route of the method
+// matching the path, and the values of its wildcards.
+func (t *routeTable) matchFallback(method, path string) (Handle, Params) {
+walk:
+	for _, p := range t.fallback[method] {
+		m := p.re.FindStringSubmatch(path)
+		if m == nil {
+			continue
+		}
+
+		var ps Params
+		for i, group := range p.groups {
+			part := &p.parts[p.wildcards[i]]
+			// e.g. an int out of range
+			if part.check != nil && !part.check(m[group]) {
+				continue walk
+			}
+			ps = append(ps, Param{Key: part.key, Value: m[group]})
+		}
+		return p.rt.tree, ps
+	}
+	return nil, nil
+}
+
+// serveFallback serves the request by the fallback matcher. It reports
+// whether a fallback route matched.
+func (r *Router) serveFallback(t *routeTable, w http.ResponseWriter, req *http.Request, hostParams Params) bool {
+	handle, ps := t.matchFallback(req.Method, req.URL.Path)
+	if handle == nil {
+		return false
+	}
+	if r.CORS != nil {
+		r.CORS.decorate(w.Header(), req.Header.Get("Origin"))
+	}
+	handle(w, req, mergeParams(hostParams, ps))
+	return true
+}
+
+// url builds the path of the pattern, see Router.URL. The values used are
+// deleted.
+func (p *pattern) url(values map[string]string) (string, error) {
+	var b strings.Builder
+	for _, part := range p.parts {
+		if part.key == "" {
+			b.WriteString(part.static)
+			continue
+		}
+
+		value, ok := values[part.key]
+		if !ok {
+			return "", fmt.Errorf("missing value for wildcard '%s' in path '%s'", part.key, p.path)
+		}
+		delete(values, part.key)
+
+		if part.catchAll {
+			if value == "" {
+				return "", fmt.Errorf("value for wildcard '%s' in path '%s' must not be empty", part.key, p.path)
+			}
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+			continue
+		}
+
+		if value == "" || strings.Contains(value, "/") {
+			return "", fmt.Errorf("value '%s' for wildcard '%s' in path '%s' must be a non-empty path segment", value, part.key, p.path)
+		}
+		if !part.re.MatchString(value) || part.check != nil && !part.check(value) {
+			return "", fmt.Errorf("value '%s' for wildcard '%s' in path '%s' does not satisfy its constraint", value, part.key, p.path)
+		}
+		b.WriteString(url.PathEscape(value))
+	}
+	return b.String(), nil
+}
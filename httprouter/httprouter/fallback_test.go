@@ -0,0 +1,193 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFitsTree(t *testing.T) {
+	tests := []struct {
+		path string
+		fits bool
+	}{
+		{"/", true},
+		{"/users/:id", true},
+		{"/users/:id<[a-z]*>/posts", true},
+		{"/v:version/users", true},
+		{"/src/*filepath", true},
+		{"/files/*path/meta", false},
+		{"/archive/:year-:month", false},
+		{"/:name.:ext", false},
+		{"/:year<int>-:month", false},
+		{"/:id<int>.json", false},
+	}
+	for _, test := range tests {
+		if fits := fitsTree(test.path); fits != test.fits {
+			t.Errorf("%s: expected %v, got %v", test.path, test.fits, fits)
+		}
+	}
+}
+
+func TestRouterFallback(t *testing.T) {
+	var route string
+	var got Params
+	handle := func(name string) Handle {
+		return func(_ http.ResponseWriter, _ *http.Request, ps Params) {
+			route, got = name, ps
+		}
+	}
+
+	router := New()
+	router.GET("/files/:name", handle("tree"))
+	router.GET("/files/*path/meta", handle("meta"))
+	router.GET("/archive/:year<int>-:month<[0-9]{2}>", handle("archive"))
+	router.GET("/archive/:name.:ext", handle("file"))
+	router.GET("/vendors", handle("vendors"))
+	router.GET("/v:version/users", handle("users"), WithFallback())
+	router.PUT("/:year<int>/:slug-:id<uuid>", handle("post"))
+
+	tests := []struct {
+		method string
+		path   string
+		route  string
+		ps     Params
+	}{
+		{http.MethodGet, "/files/meta", "tree", Params{{"name", "meta"}}},
+		{http.MethodGet, "/files/a/b/meta", "meta", Params{{"path", "a/b"}}},
+		{http.MethodGet, "/archive/2024-05", "archive", Params{{"year", "2024"}, {"month", "05"}}},
+		{http.MethodGet, "/archive/2024-5.tar", "file", Params{{"name", "2024-5"}, {"ext", "tar"}}},
+		{http.MethodGet, "/vendors", "vendors", nil},
+		{http.MethodGet, "/v2/users", "users", Params{{"version", "2"}}},
+		{http.MethodPut, "/2024/a-b-6ba7b810-9dad-11d1-80b4-00c04fd430c8", "post", Params{{"year", "2024"}, {"slug", "a-b"}, {"id", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}}},
+	}
+	for _, test := range tests {
+		route, got = "", nil
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+		if w.Code != http.StatusOK || route != test.route {
+			t.Errorf("%s %s: expected route %q, got %q (status %d)", test.method, test.path, test.route, route, w.Code)
+		}
+		if !reflect.DeepEqual(got, test.ps) {
+			t.Errorf("%s %s: expected params %v, got %v", test.method, test.path, test.ps, got)
+		}
+	}
+
+	// An int out of range does not match
+	if code := serveStatus(router, http.MethodGet, "/archive/99999999999999999999-05"); code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, code)
+	}
+
+	// The fallback routes take part in 405 and OPTIONS replies
+	if code := serveStatus(router, http.MethodGet, "/2024/a-6ba7b810-9dad-11d1-80b4-00c04fd430c8"); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, code)
+	}
+	if allow := router.allowed("/v2/users", http.MethodOptions); allow != "GET, OPTIONS" {
+		t.Errorf("expected GET to be allowed, got %q", allow)
+	}
+	if allow := router.allowed("*", ""); allow != "GET, OPTIONS, PUT" {
+		t.Errorf("expected GET and PUT to be allowed, got %q", allow)
+	}
+}
+
+func TestRouterFallbackLookup(t *testing.T) {
+	router := New()
+	router.GET("/files/:name", indexHandle)
+	router.GET("/files/*path/meta", userHandle)
+
+	handle, ps, tsr := router.Lookup(http.MethodGet, "/files/a/b/meta")
+	if handle == nil || tsr || !reflect.DeepEqual(ps, Params{{"path", "a/b"}}) {
+		t.Errorf("expected the fallback route, got %v, %v, %v", handle != nil, ps, tsr)
+	}
+	if handle, _, _ := router.Lookup(http.MethodGet, "/files/a/b/info"); handle != nil {
+		t.Error("expected no route")
+	}
+	if handle, _, _ := router.Lookup(http.MethodPost, "/files/a/b/meta"); handle != nil {
+		t.Error("expected no route for another method")
+	}
+}
+
+func TestRouterFallbackConflicts(t *testing.T) {
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router := New()
+	router.GET("/files/*path/meta", handle)
+
+	tests := []struct {
+		path     string
+		conflict string
+	}{
+		{"/files/*path/meta", "a handle is already registered for path '/files/*path/meta'"},
+		{"/files/*other/meta", "new path '/files/*other/meta' conflicts with existing fallback path '/files/*path/meta'"},
+		{"/v:version/users", ""},
+		{"/v:version<[0-9]+>/users", "new path '/v:version<[0-9]+>/users' conflicts with existing fallback path '/v:version/users'"},
+		{"/:year<int>-:month", ""},
+		{"/:name-:ext<[a-z]+>", "new path '/:name-:ext<[a-z]+>' conflicts with existing fallback path '/:year<int>-:month'"},
+	}
+	for _, test := range tests {
+		recv := catchPanic(func() { router.GET(test.path, handle, WithFallback()) })
+		if test.conflict == "" && recv != nil || test.conflict != "" && recv != test.conflict {
+			t.Errorf("%s: expected panic %q, got %v", test.path, test.conflict, recv)
+		}
+	}
+
+	// Other methods and shapes do not conflict
+	router.POST("/files/*other/meta", handle)
+	router.GET("/files/*path/info", handle)
+
+	for _, path := range []string{"/:.:ext", "/:a<[0-9]+.:b", "/:a<[0-9>.:b"} {
+		if recv := catchPanic(func() { router.GET(path, handle) }); recv == nil {
+			t.Errorf("no panic for invalid path '%s'", path)
+		}
+	}
+}
+
+func TestRouterFallbackRoutes(t *testing.T) {
+	router := New()
+	router.GET("/", indexHandle)
+	router.GET("/files/*path/meta", userHandle, WithName("meta"))
+	router.PUT("/:name.:ext", indexHandle)
+
+	var routes []string
+	for _, rt := range router.Routes() {
+		routes = append(routes, rt.Method+" "+rt.Path+" "+rt.Name)
+	}
+	expected := []string{"GET / ", "PUT /:name.:ext ", "GET /files/*path/meta meta"}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("expected routes %v, got %v", expected, routes)
+	}
+
+	var b strings.Builder
+	router.PrintTrees(&b)
+	printed := `GET
+/ [root, priority 1] main/httprouter.indexHandle
+/files/*path/meta [fallback] main/httprouter.userHandle
+PUT
+/:name.:ext [fallback] main/httprouter.indexHandle
+`
+	if b.String() != printed {
+		t.Errorf("expected\n%s\ngot\n%s", printed, b.String())
+	}
+
+	if url, err := router.URL("meta", "path", "a b/c"); err != nil || url != "/files/a%20b/c/meta" {
+		t.Errorf("expected /files/a%%20b/c/meta, got %s (%v)", url, err)
+	}
+	if url, err := router.URL("meta", "path", ""); err == nil {
+		t.Errorf("expected an error for an empty catch-all, got %s", url)
+	}
+
+	if !router.Remove(http.MethodGet, "/files/*path/meta") {
+		t.Fatal("expected the fallback route to be removed")
+	}
+	if code := serveStatus(router, http.MethodGet, "/files/a/meta"); code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, code)
+	}
+	if code := serveStatus(router, http.MethodPut, "/a.b"); code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, code)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Middleware wraps a Handle, e.g. to authenticate or log requests. The
+// returned Handle usually calls the wrapped one.
+//
+// Middlewares only run for requests matching a route, so they get the Params
+// of the route.
+type Middleware func(Handle) Handle
+
+// chain wraps the handle in the middlewares, the first one being the
+// outermost.
+func chain(handle Handle, middlewares []Middleware) Handle {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handle = middlewares[i](handle)
+	}
+	return handle
+}
+
+// Use adds middlewares to the routes registered afterwards on the router, its
+// groups and the routers returned by its Host and Builder methods, including
+// those created before. They run before the middlewares of the groups, in the
+// given order.
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// allMiddlewares returns the middlewares of the router's parents, outermost
+// first, followed by its own.
+func (r *Router) allMiddlewares() []Middleware {
+	if r.parent == nil {
+		return r.middlewares
+	}
+	parent := r.parent.allMiddlewares()
+	return append(parent[:len(parent):len(parent)], r.middlewares...)
+}
+
+// Group is a set of routes sharing a path prefix and middlewares. Its routes
+// are registered into the trees of the router which created it.
+type Group struct {
+	router      *Router
+	parent      *Group // 注册 route 的时候才取 parent 的 middlewares，之后 Use 的也算数
+	prefix      string
+	middlewares []Middleware // 只有这个 group 自己的
+}
+
+// Group returns a group of routes whose paths start with the prefix, wrapped
+// in the given middlewares. The prefix must begin with '/'; a trailing '/' is
+// ignored.
+func (r *Router) Group(prefix string, middlewares ...Middleware) *Group {
+	return (&Group{router: r}).Group(prefix, middlewares...)
+}
+
+// Group returns a sub-group, whose paths start with the prefix of the group
+// followed by the given one. Its routes are wrapped in the middlewares of the
+// group, then in the given ones.
+func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	if len(prefix) < 1 || prefix[0] != '/' {
+		panic("prefix must begin with '/' in prefix '" + prefix + "'")
+	}
+	return &Group{
+		router:      g.router,
+		parent:      g,
+		prefix:      g.prefix + strings.TrimRight(prefix, "/"),
+		middlewares: middlewares[:len(middlewares):len(middlewares)],
+	}
+}
+
+// Use adds middlewares to the routes registered afterwards on the group and
+// its sub-groups, including the sub-groups created before. They run after the
+// middlewares the group already has, in the given order.
+func (g *Group) Use(middlewares ...Middleware) {
+	g.middlewares = append(g.middlewares, middlewares...)
+}
+
+// allMiddlewares returns the middlewares of the group's parents, outermost
+// first, followed by its own.
+func (g *Group) allMiddlewares() []Middleware {
+	if g.parent == nil {
+		return g.middlewares
+	}
+	parent := g.parent.allMiddlewares()
+	return append(parent[:len(parent):len(parent)], g.middlewares...)
+}
+
+// GET is a shortcut for group.Handle(http.MethodGet, path, handle)
+func (g *Group) GET(path string, handle Handle, opts ...RouteOption) {
+	g.Handle(http.MethodGet, path, handle, opts...)
+}
+
+// HEAD is a shortcut for group.Handle(http.MethodHead, path, handle)
+func (g *Group) HEAD(path string, handle Handle, opts ...RouteOption) {
+	g.Handle(http.MethodHead, path, handle, opts...)
+}
+
+// OPTIONS is a shortcut for group.Handle(http.MethodOptions, path, handle)
+func (g *Group) OPTIONS(path string, handle Handle, opts ...RouteOption) {
+	g.Handle(http.MethodOptions, path, handle, opts...)
+}
+
+// POST is a shortcut for group.Handle(http.MethodPost, path, handle)
+func (g *Group) POST(path string, handle Handle, opts ...RouteOption) {
+	g.Handle(http.MethodPost, path, handle, opts...)
+}
+
+// PUT is a shortcut for group.Handle(http.MethodPut, path, handle)
+func (g *Group) PUT(path string, handle Handle, opts ...RouteOption) {
+	g.Handle(http.MethodPut, path, handle, opts...)
+}
+
+// PATCH is a shortcut for group.Handle(http.MethodPatch, path, handle)
+func (g *Group) PATCH(path string, handle Handle, opts ...RouteOption) {
+	g.Handle(http.MethodPatch, path, handle, opts...)
+}
+
+// DELETE is a shortcut for group.Handle(http.MethodDelete, path, handle)
+func (g *Group) DELETE(path string, handle Handle, opts ...RouteOption) {
+	g.Handle(http.MethodDelete, path, handle, opts...)
+}
+
+// Handle registers a new request handle with the prefix of the group followed
+// by the given path, which must begin with '/'. See Router.Handle.
+func (g *Group) Handle(method, path string, handle Handle, opts ...RouteOption) {
+	if len(path) < 1 || path[0] != '/' {
+		panic("path must begin with '/' in path '" + path + "'")
+	}
+	g.router.handle(method, g.prefix+path, handle, g.allMiddlewares(), opts)
+}
+
+// Handler is an adapter which allows the usage of an http.Handler as a
+// request handle of the group. See Router.Handler.
+func (g *Group) Handler(method, path string, handler http.Handler, opts ...RouteOption) {
+	g.Handle(method, path, handlerHandle(handler), opts...)
+}
+
+// HandlerFunc is an adapter which allows the usage of an http.HandlerFunc as a
+// request handle of the group.
+func (g *Group) HandlerFunc(method, path string, handler http.HandlerFunc, opts ...RouteOption) {
+	g.Handler(method, path, handler, opts...)
+}
@@ -0,0 +1,125 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordMiddleware appends the name to the trace, with the params it sees.
+func recordMiddleware(name string, trace *[]string) Middleware {
+	return func(next Handle) Handle {
+		return func(w http.ResponseWriter, r *http.Request, ps Params) {
+			*trace = append(*trace, name+":"+ps.ByName("id"))
+			next(w, r, ps)
+		}
+	}
+}
+
+func TestRouterGroup(t *testing.T) {
+	var trace []string
+	router := New()
+	router.Use(recordMiddleware("global", &trace))
+	api := router.Group("/api/", recordMiddleware("api", &trace))
+	v1 := api.Group("/v1", recordMiddleware("v1", &trace))
+	v1.Use(recordMiddleware("v1-use", &trace))
+
+	api.GET("/status", func(w http.ResponseWriter, r *http.Request, _ Params) {
+		trace = append(trace, "status")
+	})
+	v1.GET("/users/:id", func(w http.ResponseWriter, r *http.Request, ps Params) {
+		trace = append(trace, "user:"+ps.ByName("id"))
+	})
+	v1.HandlerFunc(http.MethodPost, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		trace = append(trace, "post:"+ParamsFromContext(r.Context()).ByName("id"))
+	})
+
+	tests := []struct {
+		method, path string
+		trace        []string
+	}{
+		{http.MethodGet, "/api/status", []string{"global:", "api:", "status"}},
+		{http.MethodGet, "/api/v1/users/42", []string{"global:42", "api:42", "v1:42", "v1-use:42", "user:42"}},
+		{http.MethodPost, "/api/v1/users/7", []string{"global:7", "api:7", "v1:7", "v1-use:7", "post:7"}},
+		{http.MethodGet, "/api/v1/missing", nil},
+		{http.MethodDelete, "/api/status", nil},
+	}
+	for _, test := range tests {
+		trace = nil
+		r, _ := http.NewRequest(test.method, test.path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), r)
+		if !reflect.DeepEqual(trace, test.trace) {
+			t.Errorf("%s %s: expected %v, got %v", test.method, test.path, test.trace, trace)
+		}
+	}
+
+	// Sub-groups do not share the middlewares added to their siblings.
+	admin := api.Group("/admin")
+	admin.GET("/", func(w http.ResponseWriter, r *http.Request, _ Params) {})
+	trace = nil
+	r, _ := http.NewRequest(http.MethodGet, "/api/admin/", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if expected := []string{"global:", "api:"}; !reflect.DeepEqual(trace, expected) {
+		t.Errorf("expected %v, got %v", expected, trace)
+	}
+}
+
+// Middlewares added to a group apply to its sub-groups created before.
+func TestRouterGroupUseAfterSubGroup(t *testing.T) {
+	var trace []string
+	router := New()
+	api := router.Group("/api")
+	v1 := api.Group("/v1", recordMiddleware("v1", &trace))
+	api.Use(recordMiddleware("api-use", &trace))
+	v1.GET("/users/:id", func(w http.ResponseWriter, r *http.Request, ps Params) {
+		trace = append(trace, "user:"+ps.ByName("id"))
+	})
+
+	r, _ := http.NewRequest(http.MethodGet, "/api/v1/users/3", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if expected := []string{"api-use:3", "v1:3", "user:3"}; !reflect.DeepEqual(trace, expected) {
+		t.Errorf("expected %v, got %v", expected, trace)
+	}
+}
+
+func TestRouterGroupMatchedRoutePath(t *testing.T) {
+	var matched string
+	router := New()
+	router.SaveMatchedRoutePath = true
+	router.Use(func(next Handle) Handle {
+		return func(w http.ResponseWriter, r *http.Request, ps Params) {
+			matched = ps.MatchedRoutePath()
+			next(w, r, ps)
+		}
+	})
+	router.Group("/users").GET("/:id", func(w http.ResponseWriter, r *http.Request, _ Params) {})
+
+	r, _ := http.NewRequest(http.MethodGet, "/users/1", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if matched != "/users/:id" {
+		t.Errorf("expected the middleware to see the matched route path, got %q", matched)
+	}
+}
+
+func TestRouterGroupInvalidInput(t *testing.T) {
+	router := New()
+	tests := map[string]func(){
+		"prefix without /": func() { router.Group("api") },
+		"path without /":   func() { router.Group("/api").GET("users", func(http.ResponseWriter, *http.Request, Params) {}) },
+		"nil handle":       func() { router.Group("/api").GET("/users", nil) },
+	}
+	for name, f := range tests {
+		recv := catchPanic(f)
+		if recv == nil {
+			t.Errorf("%s: expected a panic", name)
+		} else if msg, _ := recv.(string); !strings.Contains(msg, "must") {
+			t.Errorf("%s: unexpected panic %v", name, recv)
+		}
+	}
+}
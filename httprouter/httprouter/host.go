@@ -0,0 +1,163 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// hostRoute is a host pattern and the router serving its requests.
+type hostRoute struct {
+	pattern string
+	labels  []string
+	kind    nodeType // static, param or catchAll: the order they are tried in
+	router  *Router
+}
+
+// Host returns the router serving the requests whose host matches the pattern.
+// Its routes are registered as usual:
+//
+//	router.Host(":tenant.example.com").GET("/users/:id", GetUser)
+//
+// The pattern is made of dot-separated labels. Like in paths, ":name" matches a
+// single label, and a first label "*name" matches one or more labels:
+//
+//	Pattern: :tenant.example.com        acme.example.com         tenant="acme"
+//	Pattern: *sub.example.com           a.b.example.com          sub="a.b"
+//
+// Hosts are compared case-insensitively, without the port. Patterns without
+// wildcards are tried first, then those with named parameters, then those with
+// a catch-all, each in the order they were added. The host parameters come
+// first in the Params of the handle, before the path parameters.
+//
+// Requests whose host matches no pattern are routed by r itself. The returned
+// router has its own routes and settings, only the middlewares added with
+// r.Use apply to it as well, before and after Host. Calling Host again with the
+// same pattern returns the same router.
+func (r *Router) Host(pattern string) *Router {
+	pattern = strings.TrimSuffix(pattern, ".")
+	t := r.mutable()
+	for _, h := range t.hosts {
+		if h.pattern == pattern {
+			return h.router
+		}
+	}
+
+	h := &hostRoute{
+		pattern: pattern,
+		labels:  strings.Split(pattern, "."),
+		kind:    static,
+		router:  New(),
+	}
+	for i, label := range h.labels {
+		if label == "" {
+			panic("empty label in host '" + pattern + "'")
+		}
+		wildcard, start, valid := findWildcard(label)
+		if start < 0 {
+			continue
+		}
+		if start > 0 || !valid || strings.IndexByte(wildcard, '<') >= 0 {
+			panic("a wildcard must be a whole label in host '" + pattern + "'")
+		}
+		if len(wildcard) < 2 {
+			panic("wildcards must be named with a non-empty name in host '" + pattern + "'")
+		}
+		if wildcard[0] == '*' {
+			if i > 0 {
+				panic("catch-all is only allowed as the first label in host '" + pattern + "'")
+			}
+			h.kind = catchAll
+		} else if h.kind == static {
+			h.kind = param
+		}
+	}
+	h.router.parent = r
+
+	t.hosts = append(t.hosts, h)
+	t.sortHosts()
+	return h.router
+}
+
+// sortHosts orders the host patterns by the kind of their wildcards, keeping
+// the order they were added in otherwise.
+func (t *routeTable) sortHosts() {
+	sort.SliceStable(t.hosts, func(i, j int) bool {
+		return hostRank(t.hosts[i].kind) < hostRank(t.hosts[j].kind)
+	})
+}
+
+// hostRank orders the host patterns by the kind of their wildcards.
+func hostRank(kind nodeType) int {
+	switch kind {
+	case static:
+		return 0
+	case param:
+		return 1
+	}
+	return 2
+}
+
+// matchHost returns the router of the first host pattern matching the host of
+// the request, and the values of its parameters. It returns nil if no pattern
+// matches.
+func (t *routeTable) matchHost(host string) (*Router, Params) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	for _, h := range t.hosts {
+		if ps, ok := h.match(labels); ok {
+			return h.router, ps
+		}
+	}
+	return nil, nil
+}
+
+// match reports whether the host labels match the pattern, and returns the
+// values of its parameters.
+func (h *hostRoute) match(labels []string) (ps Params, ok bool) {
+	patterns := h.labels
+	if h.kind == catchAll {
+		// The catch-all takes every label the rest of the pattern does not
+		n := len(labels) - len(patterns) + 1
+		if n < 1 {
+			return nil, false
+		}
+		ps = append(ps, Param{Key: patterns[0][1:], Value: strings.Join(labels[:n], ".")})
+		patterns, labels = patterns[1:], labels[n:]
+	}
+	if len(labels) != len(patterns) {
+		return nil, false
+	}
+	for i, pattern := range patterns {
+		if pattern[0] == ':' {
+			ps = append(ps, Param{Key: pattern[1:], Value: labels[i]})
+		} else if !strings.EqualFold(pattern, labels[i]) {
+			return nil, false
+		}
+	}
+	return ps, true
+}
+
+// serveHost serves a request whose host matched one of the patterns of the
+// parent router.
+func (r *Router) serveHost(w http.ResponseWriter, req *http.Request, hostParams Params) {
+	if r.PanicHandler != nil {
+		defer r.recv(w, req)
+	}
+	r.serve(r.load(), w, req, hostParams)
+}
+
+// mergeParams returns the host parameters followed by the path parameters.
+func mergeParams(hostParams, ps Params) Params {
+	if len(hostParams) == 0 {
+		return ps
+	}
+	return append(hostParams[:len(hostParams):len(hostParams)], ps...)
+}
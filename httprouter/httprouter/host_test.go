@@ -0,0 +1,126 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRouterHost(t *testing.T) {
+	var route string
+	var got Params
+	handle := func(name string) Handle {
+		return func(_ http.ResponseWriter, _ *http.Request, ps Params) {
+			route, got = name, ps
+		}
+	}
+
+	router := New()
+	router.GET("/users/:id", handle("default"))
+	router.Host("*sub.example.com").GET("/", handle("catchAll"))
+	router.Host(":tenant.example.com").GET("/users/:id", handle("tenant"))
+	router.Host(":tenant.example.com").GET("/", handle("tenant index"))
+	router.Host("api.example.com").GET("/users/:id", handle("api"))
+
+	tests := []struct {
+		host  string
+		path  string
+		route string
+		ps    Params
+	}{
+		{"api.example.com", "/users/1", "api", Params{{"id", "1"}}},
+		{"API.Example.com:8080", "/users/1", "api", Params{{"id", "1"}}},
+		{"acme.example.com", "/users/2", "tenant", Params{{"tenant", "acme"}, {"id", "2"}}},
+		{"acme.example.com.", "/", "tenant index", Params{{"tenant", "acme"}}},
+		{"a.b.example.com", "/", "catchAll", Params{{"sub", "a.b"}}},
+		{"example.com", "/users/3", "default", Params{{"id", "3"}}},
+		{"localhost:8080", "/users/4", "default", Params{{"id", "4"}}},
+	}
+	for _, test := range tests {
+		route, got = "", nil
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req.Host = test.host
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || route != test.route {
+			t.Errorf("%s%s: expected route %q, got %q (status %d)", test.host, test.path, test.route, route, w.Code)
+		}
+		if !reflect.DeepEqual(got, test.ps) {
+			t.Errorf("%s%s: expected params %v, got %v", test.host, test.path, test.ps, got)
+		}
+	}
+
+	// A matching host does not fall back to the routes of the router
+	req := httptest.NewRequest(http.MethodGet, "/users/5", nil)
+	req.Host = "a.b.example.com"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	if router.Host("api.example.com") != router.Host("api.example.com.") {
+		t.Error("expected the same router for the same pattern")
+	}
+}
+
+func TestRouterHostMiddleware(t *testing.T) {
+	var calls int
+	router := New()
+	router.Use(func(next Handle) Handle {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			calls++
+			next(w, req, ps)
+		}
+	})
+	router.Host("api.example.com").GET("/", func(http.ResponseWriter, *http.Request, Params) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "api.example.com"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if calls != 1 {
+		t.Errorf("expected the middleware to run once, got %d", calls)
+	}
+}
+
+// Middlewares added to the router apply to its host routers created before.
+func TestRouterHostUseAfterHost(t *testing.T) {
+	var calls int
+	router := New()
+	api := router.Host("api.example.com")
+	router.Use(func(next Handle) Handle {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			calls++
+			next(w, req, ps)
+		}
+	})
+	api.GET("/", func(http.ResponseWriter, *http.Request, Params) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "api.example.com"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if calls != 1 {
+		t.Errorf("expected the middleware to run once, got %d", calls)
+	}
+}
+
+func TestRouterHostInvalid(t *testing.T) {
+	patterns := []string{
+		"",
+		"api..example.com",
+		"a:b.example.com",
+		":.example.com",
+		"api.*rest",
+		":id<int>.example.com",
+	}
+	for _, pattern := range patterns {
+		if recv := catchPanic(func() { New().Host(pattern) }); recv == nil {
+			t.Errorf("no panic for invalid host '%s'", pattern)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIDocument is an OpenAPI 3 document describing the routes of a router,
+// see Router.OpenAPI. Only the parts the router knows about are filled in.
+type OpenAPIDocument struct {
+	OpenAPI string      `json:"openapi"`
+	Info    OpenAPIInfo `json:"info"`
+
+	// path template ---> lowercase method ---> operation
+	Paths map[string]map[string]*Operation `json:"paths"`
+}
+
+// OpenAPIInfo is the metadata of an API.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Operation describes a route in an OpenAPI document.
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a parameter of an Operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // "path" or "query"
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// Response describes a response of an Operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType gives the schema of a response body.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Schema describes a value, as a subset of the JSON schemas of OpenAPI 3.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// WithSummary sets the summary of the route in the OpenAPI document.
+func WithSummary(summary string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.operation.Summary = summary
+	}
+}
+
+// WithDescription sets the description of the route in the OpenAPI document.
+func WithDescription(description string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.operation.Description = description
+	}
+}
+
+// WithTags adds tags to the route in the OpenAPI document, to group it with
+// the routes of the same tags.
+func WithTags(tags ...string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.operation.Tags = append(cfg.operation.Tags, tags...)
+	}
+}
+
+// WithParam sets the schema of the wildcard of the route with the given name
+// in the OpenAPI document. By default the schema follows the constraint of the
+// wildcard, e.g. an integer for ":id<int>", or is a string.
+func WithParam(name string, schema *Schema) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.operation.Parameters = append(cfg.operation.Parameters, &Parameter{
+			Name:   name,
+			In:     "path",
+			Schema: schema,
+		})
+	}
+}
+
+// WithQuery adds a query parameter to the route in the OpenAPI document.
+func WithQuery(name string, required bool, schema *Schema) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.operation.Parameters = append(cfg.operation.Parameters, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required,
+			Schema:   schema,
+		})
+	}
+}
+
+// WithResponse adds a response with the given status code to the route in the
+// OpenAPI document. The body is a *Schema, a value whose type is described by
+// SchemaOf as JSON, or nil if the response has no body.
+func WithResponse(status int, description string, body interface{}) RouteOption {
+	return func(cfg *routeConfig) {
+		response := &Response{Description: description}
+		if body != nil {
+			schema, ok := body.(*Schema)
+			if !ok {
+				schema = SchemaOf(body)
+			}
+			response.Content = map[string]MediaType{"application/json": {Schema: schema}}
+		}
+		if cfg.operation.Responses == nil {
+			cfg.operation.Responses = make(map[string]*Response)
+		}
+		cfg.operation.Responses[strconv.Itoa(status)] = response
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf returns the schema of the JSON encoding of the value's type, as
+// done by encoding/json: the fields of a struct are named after their json
+// tags, and are required unless tagged omitempty.
+func SchemaOf(v interface{}) *Schema {
+	return schemaOf(reflect.TypeOf(v), make(map[reflect.Type]bool))
+}
+
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"} // base64
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), seen)}
+	case reflect.Struct:
+		schema := &Schema{Type: "object"}
+		// 自己引用自己的类型，不再展开
+		if seen[t] {
+			return schema
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		schema.Properties = make(map[string]*Schema)
+		addProperties(schema, t, seen)
+		return schema
+	}
+	return &Schema{} // any value
+}
+
+// addProperties adds the fields of the struct type to the schema.
+func addProperties(schema *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.IndexByte(tag, ','); i >= 0 {
+			name, opts = tag[:i], tag[i:]
+		}
+
+		// Embedded structs without a name are flattened
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addProperties(schema, ft, seen)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = schemaOf(field.Type, seen)
+		if !strings.Contains(opts+",", ",omitempty,") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// constraintSchema returns the schema of the values of a named parameter with
+// the given constraint, which may be empty.
+func constraintSchema(expr string) *Schema {
+	switch expr {
+	case "":
+		return &Schema{Type: "string"}
+	case "int":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "uint":
+		minimum := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &minimum}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	return &Schema{Type: "string", Pattern: "^(?:" + expr + ")$"}
+}
+
+// openAPIPath converts the path of a route to an OpenAPI path template, such
+// as "/users/{id}" for "/users/:id<int>", and returns its parameters.
+func (t *routeTable) openAPIPath(path string) (string, []*Parameter) {
+	var b strings.Builder
+	var params []*Parameter
+	param := func(name, expr string) {
+		b.WriteString("{" + name + "}")
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   constraintSchema(expr),
+		})
+	}
+
+	if p := t.patterns[path]; p != nil {
+		for _, part := range p.parts {
+			if part.key == "" {
+				b.WriteString(part.static)
+			} else {
+				param(part.key, part.expr)
+			}
+		}
+		return b.String(), params
+	}
+
+	for rest := path; ; {
+		wildcard, i, _ := findWildcard(rest)
+		if i < 0 {
+			b.WriteString(rest)
+			return b.String(), params
+		}
+		b.WriteString(rest[:i])
+		rest = rest[i+len(wildcard):]
+
+		var expr string
+		if c := newConstraint(wildcard, path); c != nil {
+			expr = c.expr
+		}
+		param(paramName(wildcard), expr)
+	}
+}
+
+// openAPIMethods are the methods an OpenAPI path item can describe.
+var openAPIMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodPut:     true,
+	http.MethodPost:    true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+	http.MethodHead:    true,
+	http.MethodPatch:   true,
+	http.MethodTrace:   true,
+}
+
+// OpenAPI returns an OpenAPI 3 document describing the registered routes, with
+// the metadata given by WithSummary, WithTags, WithParam, WithResponse and so
+// on. Wildcards become path templates, ":id" and "*filepath" being "{id}" and
+// "{filepath}". The name of a route, see WithName, is its operation ID, prefixed
+// with the lowercase method if several methods share it.
+//
+// Routes with methods OpenAPI does not know are left out, as are the routes of
+// the routers returned by Host.
+func (r *Router) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	t := r.load()
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]map[string]*Operation),
+	}
+
+	named := make(map[string]int)
+	for _, rt := range t.list {
+		if rt.name != "" {
+			named[rt.name]++
+		}
+	}
+
+	for _, rt := range t.list {
+		if !openAPIMethods[rt.method] {
+			continue
+		}
+		template, params := t.openAPIPath(rt.path)
+
+		op := rt.operation // copy, the route keeps its own
+		op.OperationID = rt.name
+		if named[rt.name] > 1 {
+			op.OperationID = strings.ToLower(rt.method) + "_" + rt.name
+		}
+
+		// WithParam overrides the schema of a path parameter, WithQuery adds one
+		op.Parameters = params
+		for _, param := range rt.operation.Parameters {
+			if param.In == "query" {
+				op.Parameters = append(op.Parameters, param)
+				continue
+			}
+			for _, p := range params {
+				if p.Name == param.Name {
+					p.Schema = param.Schema
+				}
+			}
+		}
+
+		if len(op.Responses) == 0 {
+			op.Responses = map[string]*Response{"default": {Description: "Default response"}}
+		}
+
+		if doc.Paths[template] == nil {
+			doc.Paths[template] = make(map[string]*Operation)
+		}
+		doc.Paths[template][strings.ToLower(rt.method)] = &op
+	}
+	return doc
+}
+
+// JSON returns the document encoded as JSON.
+func (doc *OpenAPIDocument) JSON() ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// YAML returns the document encoded as YAML.
+func (doc *OpenAPIDocument) YAML() ([]byte, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先转成 JSON 的通用结构，再按 YAML 的格式写出来
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	writeYAML(&b, v, "")
+	return b.Bytes(), nil
+}
+
+// writeYAML writes a non-empty map or slice decoded from JSON as a YAML block,
+// each line starting with the indent.
+func writeYAML(b *bytes.Buffer, v interface{}, indent string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			b.WriteString(indent + yamlKey(key) + ":")
+			writeYAMLValue(b, v[key], indent+"  ", "\n")
+		}
+	case []interface{}:
+		for _, item := range v {
+			b.WriteString(indent + "-")
+			writeYAMLValue(b, item, indent+"  ", " ")
+		}
+	}
+}
+
+// writeYAMLValue writes the value after a key or a '-'. A non-empty map or
+// slice starts on the next line, unless the value is an item of a slice:
+// it then starts after the '-', with sep being " ".
+func writeYAMLValue(b *bytes.Buffer, v interface{}, indent, sep string) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+	case []interface{}:
+		if len(value) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+		return
+	}
+
+	if sep == "\n" {
+		b.WriteString("\n")
+		writeYAML(b, v, indent)
+		return
+	}
+	// - name: id
+	//   in: path
+	var block bytes.Buffer
+	writeYAML(&block, v, indent)
+	b.WriteString(" ")
+	b.Write(block.Bytes()[len(indent):])
+}
+
+// yamlKeywords are the plain words YAML 1.1 does not read as strings.
+var yamlKeywords = map[string]bool{
+	"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true,
+	"true": true, "false": true, "null": true,
+}
+
+// yamlKey returns the key, quoted unless it is a plain word.
+func yamlKey(key string) string {
+	if key == "" || yamlKeywords[strings.ToLower(key)] || '0' <= key[0] && key[0] <= '9' {
+		return yamlScalar(key)
+	}
+	for i := 0; i < len(key); i++ {
+		if !isNameChar(key[i]) {
+			return yamlScalar(key)
+		}
+	}
+	return key
+}
+
+// yamlScalar returns the YAML form of a scalar decoded from JSON. Strings are
+// always quoted, the JSON escapes being valid in YAML too.
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
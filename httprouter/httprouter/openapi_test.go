@@ -0,0 +1,195 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	ID      int64     `json:"id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email,omitempty"`
+	Created time.Time `json:"created"`
+	Friends []*testUser
+	Avatar  []byte `json:"-"`
+	secret  string
+	testMeta
+}
+
+type testMeta struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	user := SchemaOf(testUser{})
+	expected := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"id":      {Type: "integer", Format: "int64"},
+			"name":    {Type: "string"},
+			"email":   {Type: "string"},
+			"created": {Type: "string", Format: "date-time"},
+			"Friends": {Type: "array", Items: &Schema{Type: "object"}},
+			"labels":  {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+		},
+		Required: []string{"id", "name", "created", "Friends"},
+	}
+	if !reflect.DeepEqual(user, expected) {
+		got, _ := json.Marshal(user)
+		t.Errorf("unexpected schema %s", got)
+	}
+
+	tests := []struct {
+		v        interface{}
+		expected *Schema
+	}{
+		{true, &Schema{Type: "boolean"}},
+		{int32(1), &Schema{Type: "integer", Format: "int32"}},
+		{1.5, &Schema{Type: "number", Format: "double"}},
+		{[]byte("x"), &Schema{Type: "string", Format: "byte"}},
+		{[]string{}, &Schema{Type: "array", Items: &Schema{Type: "string"}}},
+		{nil, &Schema{}},
+	}
+	for _, test := range tests {
+		if schema := SchemaOf(test.v); !reflect.DeepEqual(schema, test.expected) {
+			t.Errorf("%T: expected %+v, got %+v", test.v, test.expected, schema)
+		}
+	}
+}
+
+func TestRouterOpenAPI(t *testing.T) {
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router := New()
+	router.GET("/users/:id<int>", handle,
+		WithName("user"),
+		WithSummary("Get a user"),
+		WithTags("users"),
+		WithResponse(http.StatusOK, "The user", testUser{}),
+		WithResponse(http.StatusNotFound, "No such user", nil),
+	)
+	router.DELETE("/users/:id<int>", handle, WithName("user"))
+	router.Group("/src").GET("/*filepath", handle,
+		WithParam("filepath", &Schema{Type: "string", Description: "file path"}),
+		WithQuery("raw", false, &Schema{Type: "boolean"}),
+	)
+	router.GET("/archive/:year<uint>-:month<[0-9]{2}>", handle)
+	router.Handle("PURGE", "/cache", handle)
+
+	doc := router.OpenAPI(OpenAPIInfo{Title: "Test", Version: "1.0"})
+	if doc.OpenAPI != "3.0.3" || doc.Info.Title != "Test" {
+		t.Errorf("unexpected document header %q %+v", doc.OpenAPI, doc.Info)
+	}
+
+	var paths []string
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	if len(paths) != 3 {
+		t.Errorf("expected 3 paths, got %v", paths)
+	}
+
+	get := doc.Paths["/users/{id}"]["get"]
+	if get == nil || doc.Paths["/users/{id}"]["delete"] == nil {
+		t.Fatalf("expected GET and DELETE /users/{id}, got %v", doc.Paths)
+	}
+	if get.OperationID != "get_user" || get.Summary != "Get a user" || !reflect.DeepEqual(get.Tags, []string{"users"}) {
+		t.Errorf("unexpected operation %+v", get)
+	}
+	id := &Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}}
+	if !reflect.DeepEqual(get.Parameters, []*Parameter{id}) {
+		t.Errorf("unexpected parameters %+v", get.Parameters)
+	}
+	if len(get.Responses) != 2 || get.Responses["200"].Content["application/json"].Schema.Type != "object" ||
+		get.Responses["404"].Content != nil {
+		t.Errorf("unexpected responses %+v", get.Responses)
+	}
+
+	src := doc.Paths["/src/{filepath}"]["get"]
+	expected := []*Parameter{
+		{Name: "filepath", In: "path", Required: true, Schema: &Schema{Type: "string", Description: "file path"}},
+		{Name: "raw", In: "query", Schema: &Schema{Type: "boolean"}},
+	}
+	if !reflect.DeepEqual(src.Parameters, expected) {
+		t.Errorf("unexpected parameters %+v", src.Parameters)
+	}
+	if src.OperationID != "" || src.Responses["default"] == nil {
+		t.Errorf("unexpected operation %+v", src)
+	}
+
+	archive := doc.Paths["/archive/{year}-{month}"]["get"]
+	minimum := 0.0
+	expected = []*Parameter{
+		{Name: "year", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64", Minimum: &minimum}},
+		{Name: "month", In: "path", Required: true, Schema: &Schema{Type: "string", Pattern: "^(?:[0-9]{2})$"}},
+	}
+	if archive == nil || !reflect.DeepEqual(archive.Parameters, expected) {
+		t.Errorf("unexpected operation %+v", archive)
+	}
+
+	if _, err := doc.JSON(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestOpenAPIYAML(t *testing.T) {
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router := New()
+	router.GET("/users/:id", handle,
+		WithName("user"),
+		WithTags("users", "on"),
+		WithQuery("on", false, &Schema{Type: "boolean"}),
+	)
+	router.POST("/users", handle, WithResponse(http.StatusCreated, "Created: \"ok\"", map[string]int{}))
+
+	data, err := router.OpenAPI(OpenAPIInfo{Title: "Test API", Version: "1.0"}).YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `info:
+  title: "Test API"
+  version: "1.0"
+openapi: "3.0.3"
+paths:
+  "/users":
+    post:
+      responses:
+        "201":
+          content:
+            "application/json":
+              schema:
+                additionalProperties:
+                  format: "int64"
+                  type: "integer"
+                type: "object"
+          description: "Created: \"ok\""
+  "/users/{id}":
+    get:
+      operationId: "user"
+      parameters:
+        - in: "path"
+          name: "id"
+          required: true
+          schema:
+            type: "string"
+        - in: "query"
+          name: "on"
+          schema:
+            type: "boolean"
+      responses:
+        default:
+          description: "Default response"
+      tags:
+        - "users"
+        - "on"
+`
+	if string(data) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, data)
+	}
+}
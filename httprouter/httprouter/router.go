@@ -53,6 +53,21 @@
 //   /blog/go/                           no match
 //   /blog/go/request-routers/comments   no match
 //
+// A named parameter can be restricted by a constraint, either int, uint, uuid
+// or a regular expression matching the whole segment. A segment not satisfying
+// the constraint does not match, so several constrained parameters and one
+// unconstrained may share a position. The constrained ones are tried first, in
+// the order they were added, and the next one is tried if the rest of the path
+// does not match below the first one satisfied:
+//  Path: /users/:id<int>              ps.Int("id")
+//  Path: /users/:slug<[a-z-]+>
+//  Path: /users/:name
+//
+//  Requests:
+//   /users/42                           match: id="42"
+//   /users/jose-maria                   match: slug="jose-maria"
+//   /users/José                         match: name="José"
+//
 // Catch-all parameters match anything until the path end, including the
 // directory index (the '/' before the catch-all). Since they match anything
 // until the end, catch-all parameters must always be the final path element.
@@ -74,6 +89,43 @@
 //  // by the index of the parameter. This way you can also get the name (key)
 //  thirdKey   := ps[2].Key   // the name of the 3rd parameter
 //  thirdValue := ps[2].Value // the value of the 3rd parameter
+//
+// Paths the radix tree cannot hold, with several wildcards in a segment or a
+// catch-all before the end, are matched by a slower fallback matcher, which is
+// only consulted if the tree has no route for the request (see WithFallback):
+//  Path: /files/*path/meta              /files/a/b/meta     match: path="a/b"
+//  Path: /archive/:year<int>-:month     /archive/2024-05    match: year="2024", month="05"
+//
+// Routes sharing a path prefix can be registered through a Group, which also
+// wraps them in middlewares. Middlewares only run for requests matching a
+// route, and get its parameters:
+//  router.Use(Logger)                   // every route registered afterwards
+//  api := router.Group("/api", Auth)
+//  api.GET("/users/:id", GetUser)       // GET /api/users/:id, logged then authenticated
+//
+// Named routes let Router.URL build their paths, instead of hard-coding them:
+//  router.GET("/hello/:name", Hello, httprouter.WithName("hello"))
+//  url, err := router.URL("hello", "name", "gopher") // "/hello/gopher"
+//
+// Router.Host returns a router for the requests to a host, matched before the
+// path. Host parameters are passed to the handle with the path parameters:
+//  router.Host(":tenant.example.com").GET("/users/:id", GetUser) // tenant, id
+//
+// Cross-origin requests are allowed by setting a CORS policy:
+//  router.CORS = &httprouter.CORS{AllowedOrigins: []string{"https://*.example.com"}}
+//
+// Routes can be described when they are registered, and Router.OpenAPI then
+// generates an OpenAPI 3 document of the registered routes:
+//  router.GET("/users/:id<int>", GetUser, httprouter.WithSummary("Get a user"),
+//      httprouter.WithResponse(200, "The user", User{}))
+//  data, err := router.OpenAPI(httprouter.OpenAPIInfo{Title: "API", Version: "1.0"}).YAML()
+//
+// Routes must be registered before serving requests. Afterwards they can be
+// removed with Router.Remove, or replaced at once through a Builder; requests
+// being served keep using the previous routes:
+//  b := router.Builder()
+//  b.GET("/hello/:name", Hello)
+//  b.Swap()
 package httprouter
 
 import (
@@ -81,6 +133,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // Handle is a function that can be registered to a route to handle HTTP
@@ -137,13 +190,19 @@ func (ps Params) MatchedRoutePath() string {
 // Router is a http.Handler which can be used to dispatch requests to different
 // handler functions via configurable routes
 type Router struct {
-	/* route 登记树 */
-	// key --> value = HTTP-Method ---> Route-Match-Tree
-	trees map[string]*node
+	/* route 登记表 */
+	// *routeTable：route tree、Params 的 sync.Pool 以及注册时的信息
+	// 注册 route 直接修改当前的表；Remove() 和 Builder 则是整张表原子替换，
+	// 正在处理的请求继续使用旧的表
+	table atomic.Value
+
+	// 串行化 Remove() 和 Builder.Swap()
+	mu sync.Mutex
 
-	// 尽可能复用 httprouter.Params 这个 slice，缓解 GC 压力
-	paramsPool sync.Pool
-	maxParams  uint16
+	// 通过 Use() 添加，注册 route 的时候裹在 handle 外面
+	middlewares []Middleware
+	// Host() 和 Builder() 创建的 router 注册 route 的时候才取 parent 的 middlewares，之后 Use 的也算数
+	parent *Router
 
 	// 除了 SaveMatchedRoutePath，其他功能标志位都是默认开启的
 	// If enabled, adds the matched route path onto the http.Request context
@@ -188,8 +247,10 @@ type Router struct {
 	// The "Allowed" header is set before calling the handler.
 	GlobalOPTIONS http.Handler
 
-	// Cached value of global (*) allowed methods
-	globalAllowed string
+	// An optional CORS policy. Preflight requests are answered by the
+	// automatic OPTIONS replies, so HandleOPTIONS must be true, and the
+	// responses of the matched handles are decorated.
+	CORS *CORS
 
 	// Configurable http.Handler which is called when no matching route is
 	// found. If it is not set, http.NotFound is used.
@@ -224,26 +285,26 @@ func New() *Router {
 	}
 }
 
-func (r *Router) getParams() *Params {
-	ps, _ := r.paramsPool.Get().(*Params)
+func (t *routeTable) getParams() *Params {
+	ps, _ := t.paramsPool.Get().(*Params)
 	*ps = (*ps)[0:0] // reset slice
 	return ps
 }
 
-func (r *Router) putParams(ps *Params) {
+func (t *routeTable) putParams(ps *Params) {
 	if ps != nil {
-		r.paramsPool.Put(ps)
+		t.paramsPool.Put(ps)
 	}
 }
 
-func (r *Router) saveMatchedRoutePath(path string, handle Handle) Handle {
+func (t *routeTable) saveMatchedRoutePath(path string, handle Handle) Handle {
 	return func(w http.ResponseWriter, req *http.Request, ps Params) {
 		if ps == nil {
-			psp := r.getParams()
+			psp := t.getParams()
 			ps = (*psp)[0:1]
 			ps[0] = Param{Key: MatchedRoutePathParam, Value: path}
 			handle(w, req, ps)
-			r.putParams(psp)
+			t.putParams(psp)
 		} else {
 			ps = append(ps, Param{Key: MatchedRoutePathParam, Value: path})
 			handle(w, req, ps)
@@ -252,38 +313,38 @@ func (r *Router) saveMatchedRoutePath(path string, handle Handle) Handle {
 }
 
 // GET is a shortcut for router.Handle(http.MethodGet, path, handle)
-func (r *Router) GET(path string, handle Handle) {
-	r.Handle(http.MethodGet, path, handle)
+func (r *Router) GET(path string, handle Handle, opts ...RouteOption) {
+	r.Handle(http.MethodGet, path, handle, opts...)
 }
 
 // HEAD is a shortcut for router.Handle(http.MethodHead, path, handle)
-func (r *Router) HEAD(path string, handle Handle) {
-	r.Handle(http.MethodHead, path, handle)
+func (r *Router) HEAD(path string, handle Handle, opts ...RouteOption) {
+	r.Handle(http.MethodHead, path, handle, opts...)
 }
 
 // OPTIONS is a shortcut for router.Handle(http.MethodOptions, path, handle)
-func (r *Router) OPTIONS(path string, handle Handle) {
-	r.Handle(http.MethodOptions, path, handle)
+func (r *Router) OPTIONS(path string, handle Handle, opts ...RouteOption) {
+	r.Handle(http.MethodOptions, path, handle, opts...)
 }
 
 // POST is a shortcut for router.Handle(http.MethodPost, path, handle)
-func (r *Router) POST(path string, handle Handle) {
-	r.Handle(http.MethodPost, path, handle)
+func (r *Router) POST(path string, handle Handle, opts ...RouteOption) {
+	r.Handle(http.MethodPost, path, handle, opts...)
 }
 
 // PUT is a shortcut for router.Handle(http.MethodPut, path, handle)
-func (r *Router) PUT(path string, handle Handle) {
-	r.Handle(http.MethodPut, path, handle)
+func (r *Router) PUT(path string, handle Handle, opts ...RouteOption) {
+	r.Handle(http.MethodPut, path, handle, opts...)
 }
 
 // PATCH is a shortcut for router.Handle(http.MethodPatch, path, handle)
-func (r *Router) PATCH(path string, handle Handle) {
-	r.Handle(http.MethodPatch, path, handle)
+func (r *Router) PATCH(path string, handle Handle, opts ...RouteOption) {
+	r.Handle(http.MethodPatch, path, handle, opts...)
 }
 
 // DELETE is a shortcut for router.Handle(http.MethodDelete, path, handle)
-func (r *Router) DELETE(path string, handle Handle) {
-	r.Handle(http.MethodDelete, path, handle)
+func (r *Router) DELETE(path string, handle Handle, opts ...RouteOption) {
+	r.Handle(http.MethodDelete, path, handle, opts...)
 }
 
 // Handle registers a new request handle with the given path and method.
@@ -297,7 +358,13 @@ func (r *Router) DELETE(path string, handle Handle) {
 // HTTP-Method + URL Path ---> handle callback function
 // 1. HTTP-Method 找到相应 method 的 route-tree
 // 2. 在通过 URL Path 找到相应的 handle callback function
-func (r *Router) Handle(method, path string, handle Handle) {
+func (r *Router) Handle(method, path string, handle Handle, opts ...RouteOption) {
+	r.handle(method, path, handle, nil, opts)
+}
+
+// handle registers the handle wrapped in the given middlewares, then in the
+// middlewares of the router and its parents.
+func (r *Router) handle(method, path string, handle Handle, middlewares []Middleware, opts []RouteOption) {
 	varsCount := uint16(0)
 
 	if method == "" {
@@ -310,38 +377,54 @@ func (r *Router) Handle(method, path string, handle Handle) {
 		panic("handle must not be nil")
 	}
 
-	if r.SaveMatchedRoutePath {
-		varsCount++
-		// 通过 chaining 的方式，裹上一层 math 记录的功能
-		handle = r.saveMatchedRoutePath(path, handle)
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if !fitsTree(path) {
+		cfg.fallback = true
 	}
 
-	if r.trees == nil {
-		r.trees = make(map[string]*node)
+	t := r.mutable()
+	if named, ok := t.names[cfg.name]; ok && named != path {
+		panic("a route named '" + cfg.name + "' is already registered for path '" + named + "'")
 	}
 
-	root := r.trees[method]
-	if root == nil {
-		root = new(node)
-		r.trees[method] = root
+	// 记下注册时的 handle，route tree 里面存的是裹了 middleware 之后的
+	rt := &route{method: method, path: path, handle: handle, routeConfig: cfg}
+
+	// middleware 只在注册时裹一次，这样只有匹配上的 route 才会执行，而且能拿到 Params
+	handle = chain(chain(handle, middlewares), r.allMiddlewares())
 
-		r.globalAllowed = r.allowed("*", "")
+	if r.SaveMatchedRoutePath {
+		varsCount++
+		// 通过 chaining 的方式，裹上一层 math 记录的功能
+		handle = t.saveMatchedRoutePath(path, handle)
 	}
 
-	// 加入 route tree 里面
-	root.addRoute(path, handle)
+	rt.tree = handle
+	rt.params = countParams(path) + varsCount
+	t.add(rt)
+}
 
-	// Update maxParams
-	if paramsCount := countParams(path); paramsCount+varsCount > r.maxParams {
-		r.maxParams = paramsCount + varsCount
-	}
+// RouteOption configures a route being registered, see Router.Handle.
+type RouteOption func(*routeConfig)
 
-	// Lazy-init paramsPool alloc func
-	if r.paramsPool.New == nil && r.maxParams > 0 {
-		r.paramsPool.New = func() interface{} {
-			ps := make(Params, 0, r.maxParams)
-			return &ps
-		}
+// routeConfig holds the options of a route being registered.
+type routeConfig struct {
+	name     string
+	fallback bool
+
+	// OpenAPI 文档里面的描述，见 WithSummary 等等
+	operation Operation
+}
+
+// WithName names the route, so that its URL can be built with Router.URL.
+// Routes with other methods may share the name, as long as they have the same
+// path.
+func WithName(name string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.name = name
 	}
 }
 
@@ -352,25 +435,29 @@ func (r *Router) Handle(method, path string, handle Handle) {
 // 注册的时候，再裹一层这个 httprouter.Router.Handler(), 原本的 http.Handler 就可以直接使用 Params 了
 // 兼容标准的 net/http 包。而 httprouter 中解析出来的东西，则通过 req.Context().Value(ParamsKey) 取出来
 // 然后 req.Context().Value(ParamsKey).(Params) 断言转换
-func (r *Router) Handler(method, path string, handler http.Handler) {
-	r.Handle(method, path,
-		func(w http.ResponseWriter, req *http.Request, p Params) {
-			if len(p) > 0 {
-				ctx := req.Context()
-				// req.Context().Value(ParamsKey) 就可以把 p 取出来了
-				ctx = context.WithValue(ctx, ParamsKey, p)
-				req = req.WithContext(ctx)
-			}
-			handler.ServeHTTP(w, req)
-		},
-	)
+func (r *Router) Handler(method, path string, handler http.Handler, opts ...RouteOption) {
+	r.Handle(method, path, handlerHandle(handler), opts...)
+}
+
+// handlerHandle returns a Handle calling the http.Handler, with the Params in
+// the request context.
+func handlerHandle(handler http.Handler) Handle {
+	return func(w http.ResponseWriter, req *http.Request, p Params) {
+		if len(p) > 0 {
+			ctx := req.Context()
+			// req.Context().Value(ParamsKey) 就可以把 p 取出来了
+			ctx = context.WithValue(ctx, ParamsKey, p)
+			req = req.WithContext(ctx)
+		}
+		handler.ServeHTTP(w, req)
+	}
 }
 
 // HandlerFunc is an adapter which allows the usage of an http.HandlerFunc as a
 // request handle.
 // httprouter.Router 与 http.Handler 之间的桥梁
-func (r *Router) HandlerFunc(method, path string, handler http.HandlerFunc) {
-	r.Handler(method, path, handler)
+func (r *Router) HandlerFunc(method, path string, handler http.HandlerFunc, opts ...RouteOption) {
+	r.Handler(method, path, handler, opts...)
 }
 
 // ServeFiles serves files from the given file system root.
@@ -404,14 +491,20 @@ func (r *Router) recv(w http.ResponseWriter, req *http.Request) {
 
 // Lookup allows the manual lookup of a method + path combo.
 // This is e.g. useful to build a framework around this router.
-// If the path was found, it returns the handle function and the path parameter
-// values. Otherwise the third return value indicates whether a redirection to
-// the same path with an extra / without the trailing slash should be performed.
+// If the path was found, in the tree or by the fallback matcher, it returns the
+// handle function and the path parameter values. Otherwise the third return
+// value indicates whether a redirection to the same path with an extra / without
+// the trailing slash should be performed.
 func (r *Router) Lookup(method, path string) (Handle, Params, bool) {
-	if root := r.trees[method]; root != nil {
-		handle, ps, tsr := root.getValue(path, r.getParams)
+	t := r.load()
+	if root := t.trees[method]; root != nil {
+		handle, ps, tsr := root.getValue(path, t.getParams)
 		if handle == nil {
-			r.putParams(ps)
+			t.putParams(ps)
+			// route tree 没有匹配上，和 serve() 一样再试试 fallback
+			if handle, ps := t.matchFallback(method, path); handle != nil {
+				return handle, ps, false
+			}
 			return nil, nil, tsr
 		}
 		if ps == nil {
@@ -422,13 +515,17 @@ func (r *Router) Lookup(method, path string) (Handle, Params, bool) {
 	return nil, nil, false
 }
 
-func (r *Router) allowed(path, reqMethod string) (allow string) {
+func (r *Router) allowed(path, reqMethod string) string {
+	return r.load().allowed(path, reqMethod)
+}
+
+func (t *routeTable) allowed(path, reqMethod string) (allow string) {
 	allowed := make([]string, 0, 9)
 
 	if path == "*" { // server-wide
 		// empty method is used for internal calls to refresh the cache
 		if reqMethod == "" {
-			for method := range r.trees {
+			for method := range t.trees {
 				if method == http.MethodOptions {
 					continue
 				}
@@ -436,16 +533,19 @@ func (r *Router) allowed(path, reqMethod string) (allow string) {
 				allowed = append(allowed, method)
 			}
 		} else {
-			return r.globalAllowed
+			return t.globalAllowed
 		}
 	} else { // specific path
-		for method := range r.trees {
+		for method := range t.trees {
 			// Skip the requested method - we already tried this one
 			if method == reqMethod || method == http.MethodOptions {
 				continue
 			}
 
-			handle, _, _ := r.trees[method].getValue(path, nil)
+			handle, _, _ := t.trees[method].getValue(path, nil)
+			if handle == nil {
+				handle, _ = t.matchFallback(method, path)
+			}
 			if handle != nil {
 				// Add request method to list of allowed methods
 				allowed = append(allowed, method)
@@ -491,24 +591,48 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		defer r.recv(w, req)
 	}
 
+	// 整个请求都用同一张表，即便中途被 Remove() 或 Builder 替换掉
+	t := r.load()
+
+	// host 匹配上了，就交给这个 host 的 Router
+	if len(t.hosts) > 0 {
+		if host, ps := t.matchHost(req.Host); host != nil {
+			host.serveHost(w, req, ps)
+			return
+		}
+	}
+
+	r.serve(t, w, req, nil)
+}
+
+// serve routes the request by its method and path, in the given route table
+// of the router. The host parameters come first in the Params of the handle.
+func (r *Router) serve(t *routeTable, w http.ResponseWriter, req *http.Request, hostParams Params) {
 	path := req.URL.Path
 
-	if root := r.trees[req.Method]; root != nil {
+	if root := t.trees[req.Method]; root != nil {
 		/* route tree match */
-		// r.getParams 是不用传递的，直接把 r 闭包进 getParams 函数
+		// t.getParams 是不用传递的，直接把 t 闭包进 getParams 函数
 		// 然后等 root.getValue() 又需要的时候才取出 Params 对象
-		if handle, ps, tsr := root.getValue(path, r.getParams); handle != nil {
+		if handle, ps, tsr := root.getValue(path, t.getParams); handle != nil {
 			/* 成功匹配，那就转发到相应的 handle function 去 */
+			// CORS 的 header 要赶在 handle 写 response 之前设置好
+			if r.CORS != nil {
+				r.CORS.decorate(w.Header(), req.Header.Get("Origin"))
+			}
 			if ps != nil {
-				handle(w, req, *ps)
+				handle(w, req, mergeParams(hostParams, *ps))
 				// Params 仅仅是这么临时用一用的而已
 				// 因为 root.getValue() 只能通过内存逃逸的方式，将生成的 Params 放在堆上
 				// 所以我们也只好通过 sync.Pool 的方式来减轻 GC 的压力
-				r.putParams(ps)
+				t.putParams(ps)
 			} else {
-				handle(w, req, nil)
+				handle(w, req, hostParams)
 			}
 			return
+		} else if r.serveFallback(t, w, req, hostParams) {
+			/* route tree 没有匹配上，再试试 fallback 里面的 route */
+			return
 		} else if req.Method != http.MethodConnect && path != "/" {
 			/* 路径修复，尝试让浏览器进行重定向 */
 			// Moved Permanently, request with GET method
@@ -546,15 +670,18 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	/* 检查相应的功能标志位，并执行相应的函数 */
 	if req.Method == http.MethodOptions && r.HandleOPTIONS {
 		// Handle OPTIONS requests
-		if allow := r.allowed(path, http.MethodOptions); allow != "" {
+		if allow := t.allowed(path, http.MethodOptions); allow != "" {
 			w.Header().Set("Allow", allow)
+			if r.CORS != nil {
+				r.CORS.preflight(w.Header(), req, allow)
+			}
 			if r.GlobalOPTIONS != nil {
 				r.GlobalOPTIONS.ServeHTTP(w, req)
 			}
 			return
 		}
 	} else if r.HandleMethodNotAllowed { // Handle 405
-		if allow := r.allowed(path, req.Method); allow != "" {
+		if allow := t.allowed(path, req.Method); allow != "" {
 			w.Header().Set("Allow", allow)
 			if r.MethodNotAllowed != nil {
 				r.MethodNotAllowed.ServeHTTP(w, req)
@@ -0,0 +1,162 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// route is what the router remembers of a registered route, besides the
+// wrapped handle in its tree.
+type route struct {
+	routeConfig
+	method, path string
+	handle       Handle // as registered, before the middlewares
+	tree         Handle // as inserted in the tree
+	params       uint16 // maximum number of Params
+}
+
+// Route describes a registered route.
+type Route struct {
+	Method string
+	Path   string // the pattern, including wildcards and the prefix of its group
+	Name   string // see WithName
+
+	// Handle is the handle as registered, before the middlewares.
+	Handle Handle
+
+	// Handler is the name of the Handle's function, such as "main.Hello".
+	// Closures are named after their enclosing function, e.g. "main.main.func1".
+	Handler string
+}
+
+// Routes returns every registered route, sorted by path then method.
+func (r *Router) Routes() []Route {
+	t := r.load()
+	var routes []Route
+	for method, root := range t.trees {
+		root.walk("", func(path string, n *node) {
+			rt := t.routes[method][path]
+			if rt == nil { // not registered through Handle
+				rt = &route{handle: n.handle}
+			}
+			routes = append(routes, rt.describe(method, path))
+		})
+	}
+	for method, patterns := range t.fallback {
+		for _, p := range patterns {
+			routes = append(routes, p.rt.describe(method, p.path))
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// describe returns the Route of the route registered with the method and path.
+func (rt *route) describe(method, path string) Route {
+	return Route{
+		Method:  method,
+		Path:    path,
+		Name:    rt.name,
+		Handle:  rt.handle,
+		Handler: funcName(rt.handle),
+	}
+}
+
+// funcName returns the name of the function.
+func funcName(f interface{}) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// walk calls fn for every node of the tree holding a handle, with its full
+// path. The nodes are visited depth first, in the order of their children.
+func (n *node) walk(prefix string, fn func(path string, n *node)) {
+	path := prefix + n.path
+	if n.handle != nil {
+		fn(path, n)
+	}
+	for _, child := range n.children {
+		child.walk(path, fn)
+	}
+}
+
+func (t nodeType) String() string {
+	switch t {
+	case static:
+		return "static"
+	case root:
+		return "root"
+	case param:
+		return "param"
+	case catchAll:
+		return "catchAll"
+	}
+	return fmt.Sprintf("nodeType(%d)", uint8(t))
+}
+
+// PrintTrees writes the radix tree of every method to w, for debugging. Each
+// node is shown with its path, type and priority, and the handler if it holds
+// one. The routes of the fallback matcher follow, in the order they are tried:
+//
+//	GET
+//	/ [root, priority 3] main.Index
+//	├── hello/ [static, priority 1]
+//	│   └── :name [param, priority 1] main.Hello
+//	└── src [static, priority 1]
+//	    └──  [catchAll, priority 1]
+//	        └── /*filepath [catchAll, priority 1] main.ServeFile
+//	/files/*path/meta [fallback] main.FileMeta
+func (r *Router) PrintTrees(w io.Writer) {
+	t := r.load()
+	methods := make([]string, 0, len(t.trees))
+	for method := range t.trees {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		fmt.Fprintln(w, method)
+		// 只有 fallback route 的 method，root 是空的
+		if root := t.trees[method]; root.path != "" || len(root.children) > 0 {
+			t.printNode(w, method, root, "", "", "")
+		}
+		for _, p := range t.fallback[method] {
+			fmt.Fprintf(w, "%s [fallback] %s\n", p.path, funcName(p.rt.handle))
+		}
+	}
+}
+
+// printNode prints the node after the indent, then its children.
+func (t *routeTable) printNode(w io.Writer, method string, n *node, prefix, indent, childIndent string) {
+	path := prefix + n.path
+	line := fmt.Sprintf("%s%s [%s, priority %d]", indent, n.path, n.nType, n.priority)
+	if n.handle != nil {
+		handle := n.handle
+		if rt := t.routes[method][path]; rt != nil {
+			handle = rt.handle
+		}
+		line += " " + funcName(handle)
+	}
+	fmt.Fprintln(w, strings.TrimRight(line, " "))
+	for i, child := range n.children {
+		if i == len(n.children)-1 {
+			t.printNode(w, method, child, path, childIndent+"└── ", childIndent+"    ")
+		} else {
+			t.printNode(w, method, child, path, childIndent+"├── ", childIndent+"│   ")
+		}
+	}
+}
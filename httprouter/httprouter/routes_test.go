@@ -0,0 +1,71 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func indexHandle(http.ResponseWriter, *http.Request, Params) {}
+
+func userHandle(http.ResponseWriter, *http.Request, Params) {}
+
+func TestRouterRoutes(t *testing.T) {
+	router := New()
+	router.Use(func(next Handle) Handle { return next })
+	router.GET("/", indexHandle, WithName("index"))
+	router.GET("/users/:id", userHandle)
+	router.DELETE("/users/:id", userHandle)
+	router.Group("/static", func(next Handle) Handle { return next }).GET("/*filepath", indexHandle)
+
+	type route struct{ method, path, name, handler string }
+	expected := []route{
+		{http.MethodGet, "/", "index", "main/httprouter.indexHandle"},
+		{http.MethodGet, "/static/*filepath", "", "main/httprouter.indexHandle"},
+		{http.MethodDelete, "/users/:id", "", "main/httprouter.userHandle"},
+		{http.MethodGet, "/users/:id", "", "main/httprouter.userHandle"},
+	}
+	var actual []route
+	for _, rt := range router.Routes() {
+		actual = append(actual, route{rt.Method, rt.Path, rt.Name, rt.Handler})
+		if rt.Handle == nil {
+			t.Errorf("%s %s: expected the registered handle", rt.Method, rt.Path)
+		}
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected routes\n%v\ngot\n%v", expected, actual)
+	}
+
+	if routes := New().Routes(); len(routes) != 0 {
+		t.Errorf("expected no routes, got %v", routes)
+	}
+}
+
+func TestRouterPrintTrees(t *testing.T) {
+	router := New()
+	router.GET("/", indexHandle)
+	router.GET("/users/:id", userHandle)
+	router.GET("/src/*filepath", indexHandle)
+	router.POST("/users", userHandle)
+
+	var b strings.Builder
+	router.PrintTrees(&b)
+	expected := `GET
+/ [root, priority 3] main/httprouter.indexHandle
+├── users/ [static, priority 1]
+│   └── :id [param, priority 1] main/httprouter.userHandle
+└── src [static, priority 1]
+    └──  [catchAll, priority 1]
+        └── /*filepath [catchAll, priority 1] main/httprouter.indexHandle
+POST
+/users [root, priority 1] main/httprouter.userHandle
+`
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+}
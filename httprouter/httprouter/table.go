@@ -0,0 +1,200 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import "sync"
+
+// routeTable holds the routes of a router. Registering a route modifies the
+// current table, while Router.Remove and Builder.Swap replace it.
+type routeTable struct {
+	// key --> value = HTTP-Method ---> Route-Match-Tree
+	trees map[string]*node
+
+	// 尽可能复用 httprouter.Params 这个 slice，缓解 GC 压力
+	paramsPool sync.Pool
+	maxParams  uint16
+
+	// Cached value of global (*) allowed methods
+	globalAllowed string
+
+	// 按注册顺序，重建 route tree 的时候使用
+	list []*route
+
+	// HTTP-Method ---> path ---> 注册时的信息，给 Router.Routes() 使用
+	routes map[string]map[string]*route
+
+	// route name ---> 注册时的 path，给 Router.URL() 使用
+	names map[string]string
+
+	// 通过 Host() 添加，在 route tree 之前先按 host 匹配
+	hosts []*hostRoute
+
+	// route tree 放不下的 route：HTTP-Method ---> 按注册顺序尝试的 pattern
+	fallback map[string][]*pattern
+
+	// 注册时的 path ---> fallback pattern，给 Router.URL() 使用
+	patterns map[string]*pattern
+}
+
+var emptyTable = new(routeTable)
+
+// load returns the current route table.
+func (r *Router) load() *routeTable {
+	if t, ok := r.table.Load().(*routeTable); ok {
+		return t
+	}
+	return emptyTable
+}
+
+// mutable returns the current route table for registering routes, creating
+// it if needed.
+func (r *Router) mutable() *routeTable {
+	if t, ok := r.table.Load().(*routeTable); ok {
+		return t
+	}
+	t := new(routeTable)
+	r.table.Store(t)
+	return t
+}
+
+// add inserts the route in the tree of its method.
+func (t *routeTable) add(rt *route) {
+	if t.trees == nil {
+		t.trees = make(map[string]*node)
+	}
+
+	root := t.trees[rt.method]
+	if root == nil {
+		root = new(node)
+		t.trees[rt.method] = root
+
+		t.globalAllowed = t.allowed("*", "")
+	}
+
+	// 加入 route tree 里面，route tree 放不下的交给 fallback
+	// 即便只有 fallback route，也要有这个 method 的 root，allowed() 才能找到它
+	if rt.fallback {
+		t.addFallback(rt)
+	} else {
+		root.addRoute(rt.path, rt.tree)
+	}
+
+	t.list = append(t.list, rt)
+	if t.routes == nil {
+		t.routes = make(map[string]map[string]*route)
+	}
+	if t.routes[rt.method] == nil {
+		t.routes[rt.method] = make(map[string]*route)
+	}
+	t.routes[rt.method][rt.path] = rt
+
+	// 有名字的 route 才能通过 Router.URL() 反向生成 URL
+	if rt.name != "" {
+		if t.names == nil {
+			t.names = make(map[string]string)
+		}
+		t.names[rt.name] = rt.path
+	}
+
+	// Update maxParams
+	if rt.params > t.maxParams {
+		t.maxParams = rt.params
+	}
+
+	// Lazy-init paramsPool alloc func
+	if t.paramsPool.New == nil && t.maxParams > 0 {
+		t.paramsPool.New = func() interface{} {
+			ps := make(Params, 0, t.maxParams)
+			return &ps
+		}
+	}
+}
+
+// Remove removes the route registered with the given method and path, the
+// path including the prefix of its group. It reports whether there was such a
+// route.
+//
+// The routes are inserted in a new table without it, which then replaces the
+// current one atomically: requests being served keep using the previous one.
+// Remove may therefore be called while serving requests, unlike Handle.
+func (r *Router) Remove(method, path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.load()
+	removed := old.routes[method][path]
+	if removed == nil {
+		return false
+	}
+
+	// route tree 不支持删除节点，只能不带它重新建一棵
+	t := &routeTable{hosts: append([]*hostRoute(nil), old.hosts...)}
+	for _, rt := range old.list {
+		if rt != removed {
+			t.add(rt)
+		}
+	}
+	r.table.Store(t)
+	return true
+}
+
+// Builder registers routes in a new route table, which replaces the routes of
+// its router at once when Swap is called:
+//
+//	b := router.Builder()
+//	b.GET("/users/:id", GetUser)
+//	b.Group("/admin", Auth).GET("/stats", Stats)
+//	b.Swap() // requests being served keep using the previous routes
+//
+// The routes are registered through the embedded Router, which uses the
+// middlewares of the router and its SaveMatchedRoutePath setting. The Builder
+// must not be used after Swap.
+//
+// The routers returned by Router.Host are kept by Swap, unless a router for the
+// same pattern was registered through the Builder, which then replaces it.
+type Builder struct {
+	*Router
+	target *Router
+}
+
+// Builder returns a Builder for a new route table of the router, initially
+// empty.
+func (r *Router) Builder() *Builder {
+	staging := New()
+	staging.SaveMatchedRoutePath = r.SaveMatchedRoutePath
+	staging.parent = r
+	return &Builder{Router: staging, target: r}
+}
+
+// Swap replaces the routes of the router with the ones registered through the
+// Builder, atomically. Requests being served keep using the previous routes.
+func (b *Builder) Swap() {
+	b.target.mu.Lock()
+	defer b.target.mu.Unlock()
+
+	// Host() 添加的 router 不在 Builder 里面，要带过去，和 Remove() 一样
+	t := b.Router.mutable()
+	hosts := t.hosts
+	t.hosts = nil
+	for _, h := range b.target.load().hosts {
+		if !hasHost(hosts, h.pattern) {
+			t.hosts = append(t.hosts, h)
+		}
+	}
+	t.hosts = append(t.hosts, hosts...)
+	t.sortHosts()
+
+	b.target.table.Store(t)
+}
+
+// hasHost reports whether one of the hosts has the pattern.
+func hasHost(hosts []*hostRoute, pattern string) bool {
+	for _, h := range hosts {
+		if h.pattern == pattern {
+			return true
+		}
+	}
+	return false
+}
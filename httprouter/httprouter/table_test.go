@@ -0,0 +1,193 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func serveStatus(router *Router, method, path string) int {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w.Code
+}
+
+func TestRouterRemove(t *testing.T) {
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router := New()
+	router.GET("/users/:id<int>", handle, WithName("user"))
+	router.GET("/users/:name", handle)
+	router.GET("/users/:name/posts", handle)
+	router.POST("/users", handle)
+
+	if router.Remove(http.MethodGet, "/users/:id") {
+		t.Error("expected no route to remove")
+	}
+	if !router.Remove(http.MethodGet, "/users/:id<int>") {
+		t.Fatal("expected the route to be removed")
+	}
+	if router.Remove(http.MethodGet, "/users/:id<int>") {
+		t.Error("expected the route to be removed only once")
+	}
+
+	// The other routes are still served, "42" now being a name
+	var name string
+	router.GET("/users/:name/likes", func(_ http.ResponseWriter, _ *http.Request, ps Params) {
+		name = ps.ByName("name")
+	})
+	if code := serveStatus(router, http.MethodGet, "/users/42/likes"); code != http.StatusOK || name != "42" {
+		t.Errorf("expected name 42, got %q (status %d)", name, code)
+	}
+	if code := serveStatus(router, http.MethodGet, "/users/42/posts"); code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if url, err := router.URL("user", "id", "42"); err == nil {
+		t.Errorf("expected the name to be removed, got %s", url)
+	}
+
+	// Removing the last route of a method removes the method
+	router.Remove(http.MethodPost, "/users")
+	if code := serveStatus(router, http.MethodPost, "/users"); code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, code)
+	}
+	if allow := router.allowed("*", ""); allow != "GET, OPTIONS" {
+		t.Errorf("expected the allowed methods to be refreshed, got %q", allow)
+	}
+	if routes := router.Routes(); len(routes) != 3 {
+		t.Errorf("expected 3 routes, got %v", routes)
+	}
+}
+
+func TestRouterRemoveInFlight(t *testing.T) {
+	router := New()
+	router.SaveMatchedRoutePath = true
+	var ps Params
+	router.GET("/users/:id", func(_ http.ResponseWriter, _ *http.Request, p Params) {
+		router.Remove(http.MethodGet, "/users/:id")
+		ps = p
+	})
+
+	if code := serveStatus(router, http.MethodGet, "/users/1"); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if ps.ByName("id") != "1" || ps.MatchedRoutePath() != "/users/:id" {
+		t.Errorf("expected the params of the removed route, got %v", ps)
+	}
+	if code := serveStatus(router, http.MethodGet, "/users/1"); code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, code)
+	}
+}
+
+func TestRouterBuilder(t *testing.T) {
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	var calls int
+	router := New()
+	router.SaveMatchedRoutePath = true
+	router.Use(func(next Handle) Handle {
+		return func(w http.ResponseWriter, req *http.Request, ps Params) {
+			calls++
+			next(w, req, ps)
+		}
+	})
+	router.GET("/old", handle)
+
+	var matched string
+	b := router.Builder()
+	b.GET("/new/:id", func(_ http.ResponseWriter, _ *http.Request, ps Params) {
+		matched = ps.MatchedRoutePath()
+	}, WithName("new"))
+	b.Group("/admin").DELETE("/cache", handle)
+
+	// Nothing changes before Swap
+	if code := serveStatus(router, http.MethodGet, "/new/1"); code != http.StatusNotFound {
+		t.Errorf("expected status %d before Swap, got %d", http.StatusNotFound, code)
+	}
+	if code := serveStatus(router, http.MethodGet, "/old"); code != http.StatusOK {
+		t.Errorf("expected status %d before Swap, got %d", http.StatusOK, code)
+	}
+
+	b.Swap()
+	if code := serveStatus(router, http.MethodGet, "/old"); code != http.StatusNotFound {
+		t.Errorf("expected status %d after Swap, got %d", http.StatusNotFound, code)
+	}
+	calls = 0
+	if code := serveStatus(router, http.MethodGet, "/new/1"); code != http.StatusOK || matched != "/new/:id" || calls != 1 {
+		t.Errorf("expected the new route with its middleware, got status %d, %q, %d calls", code, matched, calls)
+	}
+	if code := serveStatus(router, http.MethodDelete, "/admin/cache"); code != http.StatusOK {
+		t.Errorf("expected status %d after Swap, got %d", http.StatusOK, code)
+	}
+	if url, err := router.URL("new", "id", "2"); err != nil || url != "/new/2" {
+		t.Errorf("expected /new/2, got %s (%v)", url, err)
+	}
+}
+
+func TestRouterSwapHosts(t *testing.T) {
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router := New()
+	router.Host("api.example.com").GET("/v1", handle)
+	router.Host(":tenant.example.com").GET("/old", handle)
+
+	b := router.Builder()
+	b.GET("/", handle)
+	b.Host(":tenant.example.com").GET("/new", handle)
+	b.Host("*sub.example.org").GET("/", handle)
+	b.Swap()
+
+	tests := []struct {
+		host, path string
+		code       int
+	}{
+		{"api.example.com", "/v1", http.StatusOK},
+		{"acme.example.com", "/old", http.StatusNotFound},
+		{"acme.example.com", "/new", http.StatusOK},
+		{"a.b.example.org", "/", http.StatusOK},
+		{"example.net", "/", http.StatusOK},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req.Host = test.host
+		router.ServeHTTP(w, req)
+		if w.Code != test.code {
+			t.Errorf("%s%s: expected status %d, got %d", test.host, test.path, test.code, w.Code)
+		}
+	}
+	if hosts := router.load().hosts; len(hosts) != 3 || hosts[0].pattern != "api.example.com" {
+		t.Errorf("expected the static host first, got %v", hosts)
+	}
+}
+
+func TestRouterSwapConcurrent(t *testing.T) {
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router := New()
+	router.GET("/users/:id", handle)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				serveStatus(router, http.MethodGet, "/users/1")
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		b := router.Builder()
+		b.GET("/users/:id", handle)
+		b.GET("/posts/:id", handle)
+		b.Swap()
+		router.Remove(http.MethodGet, "/posts/:id")
+	}
+	wg.Wait()
+
+	if code := serveStatus(router, http.MethodGet, "/users/1"); code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, code)
+	}
+}
@@ -27,6 +27,7 @@ func longestCommonPrefix(a, b string) int {
 }
 
 // Search for a wildcard segment and check the name for invalid characters.
+// The constraint of a param, e.g. ":id<[0-9]+>", is part of the wildcard.
 // Returns -1 as index, if no wildcard was found.
 func findWildcard(path string) (wilcard string, i int, valid bool) {
 	// Find start
@@ -38,12 +39,20 @@ func findWildcard(path string) (wilcard string, i int, valid bool) {
 
 		// Find end and check for invalid characters
 		valid = true
+		constraint := false
 		for end, c := range []byte(path[start+1:]) {
 			switch c {
 			case '/':
 				return path[start : start+1+end], start, valid
+			case '<':
+				constraint = true
+			case '>':
+				constraint = false
 			case ':', '*':
-				valid = false
+				// A constraint may contain them, e.g. "<[a-z]*>"
+				if !constraint {
+					valid = false
+				}
 			}
 		}
 		return path[start:], start, valid
@@ -79,6 +88,9 @@ type node struct {
 	priority  uint32
 	children  []*node
 	handle    Handle
+
+	// param 节点的 constraint，没有则为 nil
+	constraint *constraint
 }
 
 // Increments priority of the given child and reorders if necessary
@@ -152,29 +164,37 @@ walk:
 			path = path[i:]
 
 			if n.wildChild {
-				n = n.children[0]
-				n.priority++
-
-				// Check if the wildcard matches
-				if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
-					// Adding a child to a catchAll is not possible
-					n.nType != catchAll &&
-					// Check for longer wildcard, e.g. :name and :names
-					(len(n.path) >= len(path) || path[len(n.path)] == '/') {
-					continue walk
-				} else {
-					// Wildcard conflict
-					pathSeg := path
-					if n.nType != catchAll {
-						pathSeg = strings.SplitN(pathSeg, "/", 2)[0]
+				// Check if the wildcard matches one of the wildcard children,
+				// constraint included
+				for _, child := range n.children {
+					if len(path) >= len(child.path) && child.path == path[:len(child.path)] &&
+						// Adding a child to a catchAll is not possible
+						child.nType != catchAll &&
+						// Check for longer wildcard, e.g. :name and :names
+						(len(child.path) >= len(path) || path[len(child.path)] == '/') {
+						n = child
+						n.priority++
+						continue walk
 					}
-					prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path
-					panic("'" + pathSeg +
-						"' in new path '" + fullPath +
-						"' conflicts with existing wildcard '" + n.path +
-						"' in existing prefix '" + prefix +
-						"'")
 				}
+
+				// Params with a constraint may share the position
+				if n.addParamChild(path, fullPath, handle) {
+					return
+				}
+
+				// Wildcard conflict
+				n = n.children[0]
+				pathSeg := path
+				if n.nType != catchAll {
+					pathSeg = strings.SplitN(pathSeg, "/", 2)[0]
+				}
+				prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path
+				panic("'" + pathSeg +
+					"' in new path '" + fullPath +
+					"' conflicts with existing wildcard '" + n.path +
+					"' in existing prefix '" + prefix +
+					"'")
 			}
 
 			idxc := path[0]
@@ -232,7 +252,7 @@ func (n *node) insertChild(path, fullPath string, handle Handle) {
 		}
 
 		// Check if the wildcard has a name
-		if len(wildcard) < 2 {
+		if len(wildcard) < 2 || paramName(wildcard) == "" {
 			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
 		}
 
@@ -253,8 +273,9 @@ func (n *node) insertChild(path, fullPath string, handle Handle) {
 
 			n.wildChild = true
 			child := &node{
-				nType: param,
-				path:  wildcard,
+				nType:      param,
+				path:       wildcard,
+				constraint: newConstraint(wildcard, fullPath),
 			}
 			n.children = []*node{child}
 			n = child
@@ -278,6 +299,9 @@ func (n *node) insertChild(path, fullPath string, handle Handle) {
 		}
 
 		// catchAll
+		if strings.IndexByte(wildcard, '<') > 0 {
+			panic("catch-all parameters cannot have a constraint in path '" + fullPath + "'")
+		}
 		if i+len(wildcard) != len(path) {
 			panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
 		}
@@ -327,6 +351,13 @@ func (n *node) insertChild(path, fullPath string, handle Handle) {
 // made if a handle exists with an extra (without the) trailing slash for the
 // given path.
 func (n *node) getValue(path string, params func() *Params) (handle Handle, ps *Params, tsr bool) {
+	return n.getValueFrom(path, params, nil)
+}
+
+// getValueFrom is getValue, the values of the wildcards being appended to the
+// given Params, if any.
+func (n *node) getValueFrom(path string, params func() *Params, from *Params) (handle Handle, ps *Params, tsr bool) {
+	ps = from
 walk: // Outer loop for walking the tree
 	for {
 		prefix := n.path
@@ -354,6 +385,7 @@ walk: // Outer loop for walking the tree
 				}
 
 				// Handle wildcard child
+				wild := n
 				n = n.children[0]
 				switch n.nType {
 				case param:
@@ -363,6 +395,28 @@ walk: // Outer loop for walking the tree
 						end++
 					}
 
+					// Take the first param whose constraint the value satisfies.
+					// If another one satisfies it too, the rest of the path
+					// has to match below the first one, or the next is tried.
+					var i int
+					n, i = wild.paramChild(path[:end], 0)
+					for n != nil && wild.hasParamChild(path[:end], i) {
+						saved := 0
+						if ps != nil {
+							saved = len(*ps)
+						}
+						if handle, ps = n.matchParam(path, end, params, ps); handle != nil {
+							return
+						}
+						if ps != nil {
+							*ps = (*ps)[:saved]
+						}
+						n, i = wild.paramChild(path[:end], i+1)
+					}
+					if n == nil {
+						return
+					}
+
 					// Save param value
 					if params != nil {
 						if ps == nil {
@@ -372,7 +426,7 @@ walk: // Outer loop for walking the tree
 						i := len(*ps)
 						*ps = (*ps)[:i+1]
 						(*ps)[i] = Param{
-							Key:   n.path[1:],
+							Key:   n.paramKey(),
 							Value: path[:end],
 						}
 					}
@@ -601,6 +655,7 @@ walk: // Outer loop for walking the tree
 				return nil
 			}
 
+			wild := n
 			n = n.children[0]
 			switch n.nType {
 			case param:
@@ -610,6 +665,26 @@ walk: // Outer loop for walking the tree
 					end++
 				}
 
+				// Take the first param whose constraint the value satisfies,
+				// trying the next ones if the rest of the path does not match
+				var i int
+				n, i = wild.paramChild(path[:end], 0)
+				for n != nil && wild.hasParamChild(path[:end], i) {
+					if end == len(path) && n.handle != nil {
+						return append(ciPath, path[:end]...)
+					}
+					if end < len(path) && len(n.children) > 0 {
+						out := append(ciPath[:len(ciPath):len(ciPath)], path[:end]...)
+						if out = n.children[0].findCaseInsensitivePathRec(path[end:], out, rb, false); out != nil {
+							return out
+						}
+					}
+					n, i = wild.paramChild(path[:end], i+1)
+				}
+				if n == nil {
+					return nil
+				}
+
 				// Add param value to case insensitive path
 				ciPath = append(ciPath, path[:end]...)
 
@@ -0,0 +1,104 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// URL builds the path of the route registered with the given name (see
+// WithName), filling in its parameters. The parameters are given as
+// alternating names and values:
+//
+//	router.GET("/users/:id/files/*path", ServeFile, httprouter.WithName("file"))
+//	router.URL("file", "id", "42", "path", "docs/a b.txt") // "/users/42/files/docs/a%20b.txt"
+//
+// Values are escaped. A named parameter needs a non-empty value without '/',
+// since it only matches a single path segment, which satisfies its constraint
+// if it has one. A catch-all parameter may span several segments; its leading
+// '/' is optional.
+//
+// An error is returned if there is no such route, a parameter is missing or
+// its value invalid, or a parameter is not in the path of the route.
+func (r *Router) URL(name string, params ...string) (string, error) {
+	t := r.load()
+	path, ok := t.names[name]
+	if !ok {
+		return "", fmt.Errorf("no route named '%s'", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("missing value for parameter '%s' of route '%s'", params[len(params)-1], name)
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	var built string
+	var err error
+	if p := t.patterns[path]; p != nil {
+		built, err = p.url(values)
+	} else {
+		built, err = treeURL(path, values)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(values) > 0 {
+		unknown := make([]string, 0, len(values))
+		for key := range values {
+			unknown = append(unknown, key)
+		}
+		sort.Strings(unknown)
+		return "", fmt.Errorf("unknown parameters %s for path '%s'", strings.Join(unknown, ", "), path)
+	}
+	return built, nil
+}
+
+// treeURL builds the path of a route of the tree, see Router.URL. The values
+// used are deleted.
+func treeURL(path string, values map[string]string) (string, error) {
+	var b strings.Builder
+	for rest := path; ; {
+		wildcard, i, _ := findWildcard(rest)
+		if i < 0 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:i])
+		rest = rest[i+len(wildcard):]
+
+		key := paramName(wildcard)
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("missing value for wildcard '%s' in path '%s'", wildcard, path)
+		}
+		delete(values, key)
+
+		if wildcard[0] == ':' {
+			if value == "" || strings.Contains(value, "/") {
+				return "", fmt.Errorf("value '%s' for wildcard '%s' in path '%s' must be a non-empty path segment", value, wildcard, path)
+			}
+			if c := newConstraint(wildcard, path); c != nil && !c.match(value) {
+				return "", fmt.Errorf("value '%s' for wildcard '%s' in path '%s' does not satisfy its constraint", value, wildcard, path)
+			}
+			b.WriteString(url.PathEscape(value))
+			continue
+		}
+
+		// catchAll: the '/' before it was already written
+		segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		b.WriteString(strings.Join(segments, "/"))
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterURL(t *testing.T) {
+	router := New()
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router.GET("/", handle, WithName("index"))
+	router.GET("/hello/:Params/post-id/:id", handle, WithName("post"))
+	router.PUT("/hello/:Params/post-id/:id", handle, WithName("post"))
+	router.Group("/src").GET("/*filepath", handle, WithName("src"))
+
+	tests := []struct {
+		name     string
+		params   []string
+		expected string
+	}{
+		{"index", nil, "/"},
+		{"post", []string{"Params", "aimer", "id", "123456"}, "/hello/aimer/post-id/123456"},
+		{"post", []string{"id", "1", "Params", "a b?c"}, "/hello/a%20b%3Fc/post-id/1"},
+		{"src", []string{"filepath", "/docs/read me.md"}, "/src/docs/read%20me.md"},
+		{"src", []string{"filepath", "docs/"}, "/src/docs/"},
+		{"src", []string{"filepath", ""}, "/src/"},
+	}
+	for _, test := range tests {
+		url, err := router.URL(test.name, test.params...)
+		if err != nil {
+			t.Errorf("%s %v: unexpected error %v", test.name, test.params, err)
+			continue
+		}
+		if url != test.expected {
+			t.Errorf("%s %v: expected %s, got %s", test.name, test.params, test.expected, url)
+		}
+	}
+
+	// The URLs route back to the named route.
+	var got Params
+	router.GET("/users/:name", func(_ http.ResponseWriter, _ *http.Request, ps Params) {
+		got = ps
+	}, WithName("user"))
+	url, _ := router.URL("user", "name", "José María")
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if name := got.ByName("name"); name != "José María" {
+		t.Errorf("expected the URL %s to route back, got %q", url, name)
+	}
+}
+
+func TestRouterURLErrors(t *testing.T) {
+	router := New()
+	handle := func(http.ResponseWriter, *http.Request, Params) {}
+	router.GET("/hello/:name", handle, WithName("hello"))
+
+	tests := []struct {
+		name   string
+		params []string
+	}{
+		{"unknown", nil},
+		{"hello", nil},
+		{"hello", []string{"name"}},
+		{"hello", []string{"name", ""}},
+		{"hello", []string{"name", "a/b"}},
+		{"hello", []string{"name", "gopher", "extra", "1"}},
+	}
+	for _, test := range tests {
+		if url, err := router.URL(test.name, test.params...); err == nil {
+			t.Errorf("%s %v: expected an error, got %s", test.name, test.params, url)
+		}
+	}
+
+	recv := catchPanic(func() {
+		router.GET("/bye/:name", handle, WithName("hello"))
+	})
+	if recv == nil {
+		t.Error("expected a panic for a name registered for another path")
+	}
+}